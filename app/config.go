@@ -0,0 +1,315 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"golang_jwt/audit"
+	"golang_jwt/dpop"
+	"golang_jwt/hasher"
+	"golang_jwt/helper"
+	"golang_jwt/mailer"
+	"golang_jwt/middleware"
+	"golang_jwt/policy"
+	"golang_jwt/ratelimit"
+	"golang_jwt/service"
+	"golang_jwt/webhook"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+type Config struct {
+	SecretKey      string
+	PasswordHasher hasher.Config
+	PasswordPolicy policy.Config
+	PasswordReset  service.PasswordResetConfig
+	Mailer         mailer.Config
+	Lockout        service.LockoutConfig
+	Mfa            service.MfaConfig
+	Passkey        service.PasskeyConfig
+	Passwordless   service.PasswordlessConfig
+	StepUp         middleware.StepUpConfig
+	TrustedDevice  service.TrustedDeviceConfig
+	Session        service.SessionConfig
+	SessionStore   string
+	AsnDatabase    string
+	Cookie         middleware.CookieConfig
+	Dpop           dpop.Config
+	RateLimit      ratelimit.Config
+	RateLimitStore string
+	Audit          audit.Config
+	Webhook        service.WebhookConfig
+}
+
+func NewConfig() Config {
+
+	err := godotenv.Load()
+	helper.ErrorConditionCheck(err)
+
+	argon2idParams := hasher.DefaultArgon2idParams()
+	passwordHashAlgorithm := getEnv("PASSWORD_HASH_ALGORITHM", hasher.AlgorithmArgon2id)
+	baseURL := getEnv("APP_BASE_URL", "http://localhost:3000")
+	secretKey := os.Getenv("SECRET_KEY")
+
+	return Config{
+		SecretKey: secretKey,
+		PasswordHasher: hasher.Config{
+			Algorithm: passwordHashAlgorithm,
+			Argon2id: hasher.Argon2idParams{
+				Memory:      uint32(getEnvInt("ARGON2_MEMORY_KIB", int(argon2idParams.Memory))),
+				Iterations:  uint32(getEnvInt("ARGON2_ITERATIONS", int(argon2idParams.Iterations))),
+				Parallelism: uint8(getEnvInt("ARGON2_PARALLELISM", int(argon2idParams.Parallelism))),
+				SaltLength:  uint32(getEnvInt("ARGON2_SALT_LENGTH", int(argon2idParams.SaltLength))),
+				KeyLength:   uint32(getEnvInt("ARGON2_KEY_LENGTH", int(argon2idParams.KeyLength))),
+			},
+			Pool: hasher.PoolConfig{
+				Workers:    getEnvInt("PASSWORD_HASH_WORKERS", 0),
+				QueueDepth: getEnvInt("PASSWORD_HASH_QUEUE_DEPTH", 64),
+			},
+			BcryptCost: getEnvInt("BCRYPT_COST", 10),
+		},
+		PasswordPolicy: policy.Config{
+			MinLength:             getEnvInt("PASSWORD_MIN_LENGTH", 8),
+			MaxBytes:              hasher.MaxPasswordBytes(passwordHashAlgorithm),
+			RequireUppercase:      getEnvBool("PASSWORD_REQUIRE_UPPERCASE", false),
+			RequireLowercase:      getEnvBool("PASSWORD_REQUIRE_LOWERCASE", false),
+			RequireDigit:          getEnvBool("PASSWORD_REQUIRE_DIGIT", false),
+			RequireSymbol:         getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+			MinEntropyBits:        float64(getEnvInt("PASSWORD_MIN_ENTROPY_BITS", 40)),
+			BlockUserIdentifiers:  getEnvBool("PASSWORD_BLOCK_USER_IDENTIFIERS", true),
+			BreachedPasswordsPath: getEnv("BREACHED_PASSWORDS_PATH", ""),
+		},
+		PasswordReset: service.PasswordResetConfig{
+			BaseURL:  baseURL,
+			TokenTTL: getEnvDuration("PASSWORD_RESET_TOKEN_TTL", 30*time.Minute),
+		},
+		Mailer: mailer.Config{
+			Host:     getEnv("SMTP_HOST", ""),
+			Port:     getEnvInt("SMTP_PORT", 587),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("MAIL_FROM", "no-reply@localhost"),
+		},
+		Lockout: service.LockoutConfig{
+			FailureWindow:           getEnvDuration("LOCKOUT_FAILURE_WINDOW", time.Hour),
+			AccountBackoffThreshold: getEnvInt("LOCKOUT_ACCOUNT_BACKOFF_THRESHOLD", 3),
+			AccountLockoutThreshold: getEnvInt("LOCKOUT_ACCOUNT_THRESHOLD", 10),
+			IPBackoffThreshold:      getEnvInt("LOCKOUT_IP_BACKOFF_THRESHOLD", 20),
+			IPLockoutThreshold:      getEnvInt("LOCKOUT_IP_THRESHOLD", 100),
+			BaseDelay:               getEnvDuration("LOCKOUT_BASE_DELAY", time.Second),
+			MaxDelay:                getEnvDuration("LOCKOUT_MAX_DELAY", 5*time.Minute),
+			LockoutDuration:         getEnvDuration("LOCKOUT_DURATION", 15*time.Minute),
+			UnlockTokenTTL:          getEnvDuration("UNLOCK_TOKEN_TTL", time.Hour),
+			BaseURL:                 baseURL,
+		},
+		Mfa: service.MfaConfig{
+			Issuer:        getEnv("MFA_ISSUER", "Golang JWT"),
+			EncryptionKey: getEnvKey("MFA_ENCRYPTION_KEY", "mfa:"+secretKey),
+			RecoveryCodes: getEnvInt("MFA_RECOVERY_CODES", 10),
+		},
+		Passkey: service.PasskeyConfig{
+			RPID:                    getEnv("WEBAUTHN_RP_ID", "localhost"),
+			RPName:                  getEnv("WEBAUTHN_RP_NAME", "Golang JWT"),
+			Origins:                 getEnvList("WEBAUTHN_ORIGINS", []string{baseURL}),
+			ChallengeTTL:            getEnvDuration("WEBAUTHN_CHALLENGE_TTL", 5*time.Minute),
+			RequireUserVerification: getEnvBool("WEBAUTHN_REQUIRE_USER_VERIFICATION", true),
+		},
+		Passwordless: service.PasswordlessConfig{
+			BaseURL:         baseURL,
+			LinkTTL:         getEnvDuration("PASSWORDLESS_LINK_TTL", 15*time.Minute),
+			CodeTTL:         getEnvDuration("PASSWORDLESS_CODE_TTL", 10*time.Minute),
+			MaxCodeAttempts: getEnvInt("PASSWORDLESS_MAX_CODE_ATTEMPTS", 5),
+			RateLimit:       getEnvInt("PASSWORDLESS_RATE_LIMIT", 3),
+			RateWindow:      getEnvDuration("PASSWORDLESS_RATE_WINDOW", 15*time.Minute),
+		},
+		StepUp: middleware.StepUpConfig{
+			MinAcr: getEnv("STEP_UP_MIN_ACR", ""),
+			MaxAge: getEnvDuration("STEP_UP_MAX_AGE", 10*time.Minute),
+		},
+		TrustedDevice: service.TrustedDeviceConfig{
+			TTL: getEnvDuration("TRUSTED_DEVICE_TTL", 30*24*time.Hour),
+		},
+		Session: service.SessionConfig{
+			IdleTimeout:       getEnvDuration("SESSION_IDLE_TIMEOUT", time.Hour),
+			AbsoluteLifetime:  getEnvDuration("SESSION_ABSOLUTE_LIFETIME", 24*time.Hour),
+			MaxActive:         getEnvInt("SESSION_MAX_ACTIVE", 0),
+			MaxActiveByRole:   getEnvIntMap("SESSION_MAX_ACTIVE_BY_ROLE"),
+			LimitPolicy:       getEnv("SESSION_LIMIT_POLICY", service.SessionLimitEvictOldest),
+			FingerprintPolicy: getEnv("SESSION_FINGERPRINT_POLICY", service.AnomalyPolicyFlag),
+			IpChangeHeuristic: getEnv("SESSION_IP_CHANGE_HEURISTIC", service.IpChangeHeuristicSubnet),
+			IpChangePolicy:    getEnv("SESSION_IP_CHANGE_POLICY", service.AnomalyPolicyFlag),
+		},
+		SessionStore: getEnv("SESSION_STORE", "postgres"),
+		AsnDatabase:  getEnv("ASN_DATABASE_PATH", ""),
+		Dpop: dpop.Config{
+			MaxAge:  getEnvDuration("DPOP_PROOF_MAX_AGE", time.Minute),
+			BaseURL: getEnv("DPOP_BASE_URL", ""),
+		},
+		RateLimit: ratelimit.Config{
+			Algorithm:    getEnv("RATE_LIMIT_ALGORITHM", ratelimit.AlgorithmSlidingWindow),
+			Login:        getEnvRateLimitRules("RATE_LIMIT_LOGIN", "ip:20/1m,account:5/1m"),
+			Register:     getEnvRateLimitRules("RATE_LIMIT_REGISTER", "ip:5/1h"),
+			RefreshToken: getEnvRateLimitRules("RATE_LIMIT_REFRESH_TOKEN", "ip:30/1m"),
+		},
+		RateLimitStore: getEnv("RATE_LIMIT_STORE", "memory"),
+		Audit: audit.Config{
+			CheckpointInterval: getEnvDuration("AUDIT_CHECKPOINT_INTERVAL", time.Hour),
+		},
+		Webhook: service.WebhookConfig{
+			EncryptionKey:    getEnvKey("WEBHOOK_ENCRYPTION_KEY", "webhook:"+secretKey),
+			DispatchInterval: getEnvDuration("WEBHOOK_DISPATCH_INTERVAL", 5*time.Second),
+			BatchSize:        getEnvInt("WEBHOOK_BATCH_SIZE", 50),
+			MaxAttempts:      getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			RetryBaseDelay:   getEnvDuration("WEBHOOK_RETRY_BASE_DELAY", 30*time.Second),
+			RetryMaxDelay:    getEnvDuration("WEBHOOK_RETRY_MAX_DELAY", time.Hour),
+			Sender: webhook.Config{
+				Timeout: getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			},
+		},
+		Cookie: middleware.CookieConfig{
+			Enabled:     getEnvBool("SESSION_COOKIE_MODE", false),
+			AccessToken: getEnvBool("SESSION_COOKIE_ACCESS_TOKEN", true),
+			Secure:      getEnvBool("SESSION_COOKIE_SECURE", true),
+			SameSite:    getEnvSameSite("SESSION_COOKIE_SAMESITE", http.SameSiteStrictMode),
+			Domain:      getEnv("SESSION_COOKIE_DOMAIN", ""),
+		},
+	}
+}
+
+func getEnv(key string, fallback string) string {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	return value
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(getEnv(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(getEnv(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func getEnvList(key string, fallback []string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return fallback
+	}
+	return values
+}
+
+// getEnvIntMap parses a list such as "admin=1,user=3". Malformed entries are
+// skipped.
+func getEnvIntMap(key string) map[string]int {
+	values := map[string]int{}
+	for _, entry := range getEnvList(key, nil) {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		number, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		values[strings.TrimSpace(name)] = number
+	}
+	return values
+}
+
+// getEnvRateLimitRules parses a list such as "ip:20/1m,account:5/1m", where
+// each entry is key:limit/window. "off" disables the limit; any other entry
+// that cannot be parsed stops the startup, so a typo never turns it off.
+func getEnvRateLimitRules(key string, fallback string) []ratelimit.Rule {
+	value := getEnv(key, fallback)
+	if strings.EqualFold(strings.TrimSpace(value), "off") {
+		return nil
+	}
+
+	var rules []ratelimit.Rule
+	for _, entry := range strings.Split(value, ",") {
+		rule, ok := parseRateLimitRule(strings.TrimSpace(entry))
+		if !ok {
+			log.Fatalf("%s: invalid rate limit rule %q, expected key:limit/window with key ip, account or client", key, entry)
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func parseRateLimitRule(entry string) (ratelimit.Rule, bool) {
+	name, quota, ok := strings.Cut(entry, ":")
+	if !ok || (name != ratelimit.KeyIP && name != ratelimit.KeyAccount && name != ratelimit.KeyClient) {
+		return ratelimit.Rule{}, false
+	}
+	limit, window, ok := strings.Cut(quota, "/")
+	if !ok {
+		return ratelimit.Rule{}, false
+	}
+	number, err := strconv.Atoi(limit)
+	if err != nil || number <= 0 {
+		return ratelimit.Rule{}, false
+	}
+	duration, err := time.ParseDuration(window)
+	if err != nil || duration <= 0 {
+		return ratelimit.Rule{}, false
+	}
+	return ratelimit.Rule{Key: name, Limit: number, Window: duration}, true
+}
+
+func getEnvSameSite(key string, fallback http.SameSite) http.SameSite {
+	switch strings.ToLower(getEnv(key, "")) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "lax":
+		return http.SameSiteLaxMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return fallback
+	}
+}
+
+// getEnvKey decodes a base64 encoded 32-byte key. When the variable is not
+// set, a key is derived from fallbackSeed so development setups keep working;
+// a value that is set but invalid, such as a placeholder, stops the startup.
+func getEnvKey(key string, fallbackSeed string) []byte {
+	encoded := getEnv(key, "")
+	if encoded == "" {
+		derived := sha256.Sum256([]byte(fallbackSeed))
+		return derived[:]
+	}
+
+	value, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(value) != 32 {
+		log.Fatalf("%s must be a base64 encoded 32-byte key", key)
+	}
+	return value
+}
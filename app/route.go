@@ -3,26 +3,77 @@ package app
 import (
 	"github.com/julienschmidt/httprouter"
 	"golang_jwt/controller"
+	"golang_jwt/dpop"
 	"golang_jwt/exception"
+	"golang_jwt/model/domain"
+	"golang_jwt/ratelimit"
 	"golang_jwt/token"
 	"golang_jwt/middleware"
 )
 
-func NewRouter(userController controller.UserController, userToken token.UserToken) *httprouter.Router {
+func NewRouter(userController controller.UserController, passwordController controller.PasswordController, lockoutController controller.LockoutController, mfaController controller.MfaController, passkeyController controller.PasskeyController, passwordlessController controller.PasswordlessController, trustedDeviceController controller.TrustedDeviceController, metricsController controller.MetricsController, auditController controller.AuditController, webhookController controller.WebhookController, userToken token.UserToken, tokenVersionChecker middleware.TokenVersionChecker, stepUpConfig middleware.StepUpConfig, cookieConfig middleware.CookieConfig, dpopVerifier *dpop.Verifier, rateLimiter *ratelimit.Limiter, rateLimitConfig ratelimit.Config) *httprouter.Router {
 	router := httprouter.New()
 
 	// Public endpoints (tidak perlu authentication)
-	router.POST("/api/register", userController.Register)
-	router.POST("/api/users/login", userController.Login)
-	router.POST("/api/users/refresh-token", userController.RenewAccessToken)
+	// dpopProof: endpoint yang menerbitkan token (token terikat ke key DPoP client)
+	// *Limit: batas jumlah request per IP / akun / client
+	dpopProof := middleware.AcceptDpopProof(dpopVerifier)
+	registerLimit := middleware.RateLimit(rateLimiter, "register", rateLimitConfig.Register)
+	loginLimit := middleware.RateLimit(rateLimiter, "login", rateLimitConfig.Login)
+	refreshTokenLimit := middleware.RateLimit(rateLimiter, "refresh-token", rateLimitConfig.RefreshToken)
+	router.POST("/api/register", registerLimit(userController.Register))
+	router.POST("/api/users/login", loginLimit(dpopProof(userController.Login)))
+	router.POST("/api/users/login/mfa", dpopProof(mfaController.CompleteLogin))
+	router.POST("/api/users/login/passkey/options", passkeyController.BeginLogin)
+	router.POST("/api/users/login/passkey", dpopProof(passkeyController.FinishLogin))
+	router.POST("/api/users/login/passwordless", passwordlessController.RequestLogin)
+	router.POST("/api/users/login/passwordless/verify", dpopProof(passwordlessController.VerifyLogin))
+	router.POST("/api/users/refresh-token", refreshTokenLimit(dpopProof(userController.RenewAccessToken)))
+	router.POST("/api/users/password/forgot", passwordController.ForgotPassword)
+	router.POST("/api/users/password/reset", passwordController.ResetPassword)
+	router.POST("/api/users/unlock", lockoutController.RedeemUnlockToken)
 
 	// Protected endpoints (perlu authentication)
-	authMiddleware := middleware.CreateAuthMiddleware(userToken)
+	// stepUp: endpoint sensitif (perlu login ulang yang masih baru)
+	authMiddleware := middleware.CreateAuthMiddleware(userToken, tokenVersionChecker, cookieConfig, dpopVerifier)
+	stepUp := middleware.RequireStepUp(stepUpConfig)
 	router.POST("/api/users/logout", authMiddleware(userController.Logout))
+	router.POST("/api/users/me/logout-all", authMiddleware(userController.LogoutAll))
 	router.POST("/api/users/revoke-session", authMiddleware(userController.RevokeSession))
+	router.POST("/api/users/me/reauthenticate", authMiddleware(userController.Reauthenticate))
+	router.DELETE("/api/users/me", authMiddleware(stepUp(userController.DeleteAccount)))
+	router.PUT("/api/users/me/password", authMiddleware(stepUp(passwordController.ChangePassword)))
+	router.POST("/api/users/me/mfa/totp", authMiddleware(stepUp(mfaController.EnrollTotp)))
+	router.POST("/api/users/me/mfa/totp/confirm", authMiddleware(stepUp(mfaController.ConfirmTotp)))
+	router.POST("/api/users/me/mfa/totp/disable", authMiddleware(stepUp(mfaController.DisableTotp)))
+	router.POST("/api/users/me/mfa/recovery-codes", authMiddleware(stepUp(mfaController.RegenerateRecoveryCodes)))
+	router.GET("/api/users/:userId/mfa/recovery-codes", authMiddleware(mfaController.RecoveryCodeStatus))
+	router.POST("/api/users/me/passkeys/options", authMiddleware(stepUp(passkeyController.BeginRegistration)))
+	router.POST("/api/users/me/passkeys", authMiddleware(passkeyController.FinishRegistration))
+	router.DELETE("/api/users/me/passkeys/:credentialId", authMiddleware(stepUp(passkeyController.Delete)))
+	router.GET("/api/users/:userId/passkeys", authMiddleware(passkeyController.FindAll))
+	router.DELETE("/api/users/me/sessions/:sessionId", authMiddleware(userController.DeleteSession))
+	router.GET("/api/users/:userId/sessions", authMiddleware(userController.FindSessions))
+	router.DELETE("/api/users/me/devices/:deviceId", authMiddleware(trustedDeviceController.Revoke))
+	router.GET("/api/users/:userId/devices", authMiddleware(trustedDeviceController.FindAll))
 	router.GET("/api/users/:userId", authMiddleware(userController.FindById))
 	router.GET("/api/users", authMiddleware(userController.FindAll))
 
+	// Admin endpoints (perlu role admin)
+	adminOnly := middleware.RequireRole(domain.RoleAdmin)
+	router.POST("/api/admin/users/:userId/unlock", authMiddleware(adminOnly(lockoutController.UnlockAccount)))
+	router.POST("/api/admin/users/:userId/logout-all", authMiddleware(adminOnly(userController.LogoutAll)))
+	router.POST("/api/admin/sessions/:sessionId/revoke", authMiddleware(adminOnly(userController.RevokeSession)))
+	router.GET("/api/admin/metrics/password-hashing", authMiddleware(adminOnly(metricsController.PasswordHashing)))
+	router.GET("/api/admin/audit-events", authMiddleware(adminOnly(auditController.FindAll)))
+	router.GET("/api/admin/audit-events/verify", authMiddleware(adminOnly(auditController.VerifyChain)))
+	router.POST("/api/admin/webhooks", authMiddleware(adminOnly(webhookController.Create)))
+	router.GET("/api/admin/webhooks", authMiddleware(adminOnly(webhookController.FindAll)))
+	router.DELETE("/api/admin/webhooks/:webhookId", authMiddleware(adminOnly(webhookController.Delete)))
+	router.GET("/api/admin/webhook-deliveries", authMiddleware(adminOnly(webhookController.FindDeliveries)))
+	router.GET("/api/admin/webhook-deliveries/dead-letters", authMiddleware(adminOnly(webhookController.FindDeadLetters)))
+	router.POST("/api/admin/webhook-deliveries/:deliveryId/redeliver", authMiddleware(adminOnly(webhookController.Redeliver)))
+
 	router.PanicHandler = exception.ErrorHandler
 
 	return router
@@ -0,0 +1,108 @@
+package asn
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FileResolverImpl reads a local prefix table, one "CIDR,ASN" pair per line
+// (e.g. 203.0.113.0/24,64500), as can be exported from public IP-to-ASN
+// datasets. Blank lines and lines starting with # are ignored. The file is
+// loaded on first use; the longest matching prefix wins.
+type FileResolverImpl struct {
+	Path string
+
+	once sync.Once
+	ipv4 prefixTable
+	ipv6 prefixTable
+	err  error
+}
+
+// prefixTable indexes networks by prefix length, longest first.
+type prefixTable struct {
+	lengths  []int
+	networks map[int]map[string]uint32
+}
+
+func NewFileResolver(path string) Resolver {
+	return &FileResolverImpl{
+		Path: path,
+	}
+}
+
+func (resolver *FileResolverImpl) Lookup(ip net.IP) (uint32, bool) {
+	resolver.once.Do(resolver.load)
+	if resolver.err != nil || ip == nil {
+		return 0, false
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		return resolver.ipv4.lookup(ip4, 32)
+	}
+	return resolver.ipv6.lookup(ip, 128)
+}
+
+func (table *prefixTable) lookup(ip net.IP, bits int) (uint32, bool) {
+	for _, length := range table.lengths {
+		network := ip.Mask(net.CIDRMask(length, bits))
+		if number, ok := table.networks[length][network.String()]; ok {
+			return number, true
+		}
+	}
+	return 0, false
+}
+
+func (table *prefixTable) add(network *net.IPNet, number uint32) {
+	length, _ := network.Mask.Size()
+	if table.networks == nil {
+		table.networks = map[int]map[string]uint32{}
+	}
+	if table.networks[length] == nil {
+		table.networks[length] = map[string]uint32{}
+		table.lengths = append(table.lengths, length)
+		sort.Sort(sort.Reverse(sort.IntSlice(table.lengths)))
+	}
+	table.networks[length][network.IP.String()] = number
+}
+
+func (resolver *FileResolverImpl) load() {
+	file, err := os.Open(resolver.Path)
+	if err != nil {
+		resolver.err = err
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		cidr, number, ok := strings.Cut(line, ",")
+		if !ok {
+			continue
+		}
+		_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			continue
+		}
+		asNumber, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimSpace(number), "AS"), 10, 32)
+		if err != nil {
+			continue
+		}
+
+		if network.IP.To4() != nil {
+			resolver.ipv4.add(network, uint32(asNumber))
+		} else {
+			resolver.ipv6.add(network, uint32(asNumber))
+		}
+	}
+	resolver.err = scanner.Err()
+}
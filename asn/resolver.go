@@ -0,0 +1,8 @@
+package asn
+
+import "net"
+
+// Resolver maps an IP address to its autonomous system number.
+type Resolver interface {
+	Lookup(ip net.IP) (uint32, bool)
+}
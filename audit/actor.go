@@ -0,0 +1,22 @@
+package audit
+
+import "context"
+
+type actorKey struct{}
+
+// Actor is the authenticated caller of the current request.
+type Actor struct {
+	UserID    int
+	SessionID string
+}
+
+// WithActor is called by the auth middleware, so events recorded further
+// down know who acted even when they are about another user.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func ActorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	return actor
+}
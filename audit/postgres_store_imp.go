@@ -0,0 +1,311 @@
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PostgresStoreImpl appends events to the audit_events table. It writes
+// through the pool rather than the caller's transaction, so an event stays
+// recorded when the business transaction it describes is rolled back.
+//
+// Each record carries the hash of the one before it. Appends take an
+// advisory lock, so records are chained in id order even when written by
+// several instances at once.
+type PostgresStoreImpl struct {
+	DB         *sql.DB
+	SigningKey []byte
+}
+
+func NewPostgresStore(db *sql.DB, signingKey []byte) Store {
+	return &PostgresStoreImpl{
+		DB:         db,
+		SigningKey: signingKey,
+	}
+}
+
+const (
+	recordTimeout = 5 * time.Second
+	chainLockId   = 4_715_220_491
+	verifyBatch   = 1000
+)
+
+const eventColumns = "id, event_type, outcome, actor_id, user_id, session_id, ip_address, user_agent, metadata, occurred_at, previous_hash, hash"
+
+func (store *PostgresStoreImpl) Record(ctx context.Context, event Event) (err error) {
+	metadata, err := json.Marshal(event.Metadata)
+	if err != nil {
+		return err
+	}
+	if event.Metadata == nil {
+		metadata = []byte("{}")
+	}
+
+	// The request may already be cancelled, e.g. when a failed login is
+	// recorded after the client went away; the event is kept regardless.
+	// The caller usually holds another connection in its transaction, so
+	// the wait for a free one is bounded rather than risking a pool deadlock.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+
+	tx, err := store.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", chainLockId)
+	if err != nil {
+		return err
+	}
+
+	SQL := "SELECT hash FROM audit_events ORDER BY id DESC LIMIT 1"
+	err = tx.QueryRowContext(ctx, SQL).Scan(&event.PreviousHash)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	event.OccurredAt = storedTime(event.OccurredAt)
+	event.Hash = event.chainHash()
+
+	SQL = "INSERT INTO audit_events (event_type, outcome, actor_id, user_id, session_id, ip_address, user_agent, metadata, occurred_at, previous_hash, hash) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)"
+	_, err = tx.ExecContext(ctx, SQL,
+		string(event.Type), string(event.Outcome), nullableId(event.ActorID), nullableId(event.UserID),
+		event.SessionID, event.IPAddress, event.UserAgent, string(metadata), event.OccurredAt,
+		event.PreviousHash, event.Hash)
+	return err
+}
+
+func (store *PostgresStoreImpl) Find(ctx context.Context, filter Filter) ([]Event, error) {
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.Replace(condition, "?", "$"+strconv.Itoa(len(args)), 1))
+	}
+
+	if filter.Type != "" {
+		where("event_type = ?", string(filter.Type))
+	}
+	if filter.Outcome != "" {
+		where("outcome = ?", string(filter.Outcome))
+	}
+	if filter.ActorID != 0 {
+		where("actor_id = ?", filter.ActorID)
+	}
+	if filter.UserID != 0 {
+		where("user_id = ?", filter.UserID)
+	}
+	if filter.SessionID != "" {
+		where("session_id = ?", filter.SessionID)
+	}
+	if filter.IPAddress != "" {
+		where("ip_address = ?", filter.IPAddress)
+	}
+	if !filter.From.IsZero() {
+		where("occurred_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("occurred_at < ?", filter.To)
+	}
+	if filter.BeforeID != 0 {
+		where("id < ?", filter.BeforeID)
+	}
+
+	SQL := "SELECT " + eventColumns + " FROM audit_events"
+	if len(conditions) > 0 {
+		SQL += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	SQL += " ORDER BY id DESC LIMIT $" + strconv.Itoa(len(args))
+
+	rows, err := store.DB.QueryContext(ctx, SQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func scanEvent(rows *sql.Rows) (Event, error) {
+	event := Event{}
+	var eventType, outcome, metadata string
+	var actorId, userId sql.NullInt64
+	err := rows.Scan(&event.ID, &eventType, &outcome, &actorId, &userId, &event.SessionID,
+		&event.IPAddress, &event.UserAgent, &metadata, &event.OccurredAt, &event.PreviousHash, &event.Hash)
+	if err != nil {
+		return event, err
+	}
+
+	event.Type = EventType(eventType)
+	event.Outcome = Outcome(outcome)
+	event.ActorID = int(actorId.Int64)
+	event.UserID = int(userId.Int64)
+	err = json.Unmarshal([]byte(metadata), &event.Metadata)
+	return event, err
+}
+
+func (store *PostgresStoreImpl) CreateCheckpoint(ctx context.Context) (Checkpoint, bool, error) {
+	checkpoint := Checkpoint{}
+	SQL := "SELECT id, hash FROM audit_events ORDER BY id DESC LIMIT 1"
+	err := store.DB.QueryRowContext(ctx, SQL).Scan(&checkpoint.EventID, &checkpoint.Hash)
+	if err == sql.ErrNoRows {
+		return checkpoint, false, nil
+	}
+	if err != nil {
+		return checkpoint, false, err
+	}
+
+	var lastEventId int64
+	SQL = "SELECT event_id FROM audit_checkpoints ORDER BY id DESC LIMIT 1"
+	err = store.DB.QueryRowContext(ctx, SQL).Scan(&lastEventId)
+	if err != nil && err != sql.ErrNoRows {
+		return checkpoint, false, err
+	}
+	if lastEventId == checkpoint.EventID {
+		return checkpoint, false, nil
+	}
+
+	checkpoint.CreatedAt = storedTime(time.Now())
+	checkpoint.Signature = signCheckpoint(store.SigningKey, checkpoint)
+
+	SQL = "INSERT INTO audit_checkpoints (event_id, hash, signature, created_at) VALUES ($1, $2, $3, $4) RETURNING id"
+	err = store.DB.QueryRowContext(ctx, SQL, checkpoint.EventID, checkpoint.Hash, checkpoint.Signature, checkpoint.CreatedAt).Scan(&checkpoint.ID)
+	if err != nil {
+		return checkpoint, false, err
+	}
+	return checkpoint, true, nil
+}
+
+// VerifyChain walks all records in id order. A record breaks the chain when
+// its hash does not match its content, when its previous_hash is not the
+// hash of the record before it (a record was removed or inserted), or when
+// it differs from a checkpoint. Checkpoints whose record is gone, e.g.
+// after the end of the chain was cut off, break it as well.
+func (store *PostgresStoreImpl) VerifyChain(ctx context.Context) (ChainVerification, error) {
+	verification := ChainVerification{}
+	checkpoints, err := store.findCheckpoints(ctx)
+	if err != nil {
+		return verification, err
+	}
+
+	for _, checkpoint := range checkpoints {
+		expected := signCheckpoint(store.SigningKey, checkpoint)
+		if !hmac.Equal([]byte(expected), []byte(checkpoint.Signature)) {
+			return verification.broken(checkpoint.EventID, fmt.Sprintf("checkpoint %d has an invalid signature", checkpoint.ID)), nil
+		}
+	}
+
+	checkpointsByEvent := map[int64]Checkpoint{}
+	for _, checkpoint := range checkpoints {
+		checkpointsByEvent[checkpoint.EventID] = checkpoint
+	}
+
+	previousHash := ""
+	for {
+		SQL := "SELECT " + eventColumns + " FROM audit_events WHERE id > $1 ORDER BY id LIMIT $2"
+		rows, err := store.DB.QueryContext(ctx, SQL, verification.LastEventID, verifyBatch)
+		if err != nil {
+			return verification, err
+		}
+
+		var events []Event
+		for rows.Next() {
+			event, err := scanEvent(rows)
+			if err != nil {
+				rows.Close()
+				return verification, err
+			}
+			events = append(events, event)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return verification, err
+		}
+		if len(events) == 0 {
+			break
+		}
+
+		for _, event := range events {
+			switch {
+			case event.PreviousHash != previousHash:
+				return verification.broken(event.ID, "previous_hash does not match the record before it"), nil
+			case event.chainHash() != event.Hash:
+				return verification.broken(event.ID, "record content does not match its hash"), nil
+			}
+
+			if checkpoint, ok := checkpointsByEvent[event.ID]; ok {
+				if checkpoint.Hash != event.Hash {
+					return verification.broken(event.ID, fmt.Sprintf("record does not match checkpoint %d", checkpoint.ID)), nil
+				}
+				delete(checkpointsByEvent, event.ID)
+				verification.CheckedCheckpoints++
+			}
+
+			previousHash = event.Hash
+			verification.LastEventID = event.ID
+			verification.CheckedEvents++
+		}
+	}
+
+	for _, checkpoint := range checkpoints {
+		if _, missing := checkpointsByEvent[checkpoint.EventID]; missing {
+			return verification.broken(checkpoint.EventID, fmt.Sprintf("record of checkpoint %d is missing", checkpoint.ID)), nil
+		}
+	}
+
+	verification.Valid = true
+	return verification, nil
+}
+
+func (store *PostgresStoreImpl) findCheckpoints(ctx context.Context) ([]Checkpoint, error) {
+	SQL := "SELECT id, event_id, hash, signature, created_at FROM audit_checkpoints ORDER BY event_id"
+	rows, err := store.DB.QueryContext(ctx, SQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var checkpoints []Checkpoint
+	for rows.Next() {
+		checkpoint := Checkpoint{}
+		err := rows.Scan(&checkpoint.ID, &checkpoint.EventID, &checkpoint.Hash, &checkpoint.Signature, &checkpoint.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	return checkpoints, rows.Err()
+}
+
+func (verification ChainVerification) broken(eventId int64, reason string) ChainVerification {
+	verification.BrokenEventID = eventId
+	verification.Reason = reason
+	return verification
+}
+
+// nullableId stores a missing user as NULL rather than 0.
+func nullableId(id int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(id), Valid: id != 0}
+}
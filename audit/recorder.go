@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventUserRegistered            EventType = "user.registered"
+	EventLogin                     EventType = "auth.login"
+	EventReauthentication          EventType = "auth.reauthentication"
+	EventSessionRenewed            EventType = "session.renewed"
+	EventSessionRevoked            EventType = "session.revoked"
+	EventSessionsRevokedAll        EventType = "session.revoked_all"
+	EventRecoveryCodesGenerated    EventType = "mfa.recovery_codes.generated"
+	EventRecoveryCodeUsed          EventType = "mfa.recovery_code.used"
+	EventRecoveryCodeRejected      EventType = "mfa.recovery_code.rejected"
+	EventSessionEvicted            EventType = "session.evicted"
+	EventSessionLimitReached       EventType = "session.limit_reached"
+	EventSessionFingerprintChanged EventType = "session.fingerprint_changed"
+	EventSessionIpChanged          EventType = "session.ip_changed"
+	EventAccountUnlocked           EventType = "account.unlocked"
+)
+
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+)
+
+// Event describes one security relevant action. ActorID is the user who
+// acted, zero when the caller was not authenticated; UserID is the account
+// the event is about. They differ for example when an admin revokes another
+// user's sessions. Stores that keep a hash chain fill in PreviousHash and
+// Hash when the event is recorded.
+type Event struct {
+	ID           int64
+	Type         EventType
+	Outcome      Outcome
+	ActorID      int
+	UserID       int
+	SessionID    string
+	IPAddress    string
+	UserAgent    string
+	Metadata     map[string]string
+	OccurredAt   time.Time
+	PreviousHash string
+	Hash         string
+}
+
+// Recorder stores security relevant events. Callers should not fail the
+// request when recording fails; the error is returned for logging.
+type Recorder interface {
+	Record(ctx context.Context, event Event) error
+}
+
+// Filter selects events for Store.Find. Zero fields do not filter. Results
+// are ordered newest first; BeforeID pages through older events.
+type Filter struct {
+	Type      EventType
+	Outcome   Outcome
+	ActorID   int
+	UserID    int
+	SessionID string
+	IPAddress string
+	From      time.Time
+	To        time.Time
+	BeforeID  int64
+	Limit     int
+}
+
+// Store is a Recorder whose events can be queried again and whose records
+// are chained, so later edits can be detected.
+type Store interface {
+	Recorder
+	Find(ctx context.Context, filter Filter) ([]Event, error)
+	// CreateCheckpoint signs the current end of the chain. It reports false
+	// when there is nothing new since the last checkpoint.
+	CreateCheckpoint(ctx context.Context) (Checkpoint, bool, error)
+	VerifyChain(ctx context.Context) (ChainVerification, error)
+}
@@ -0,0 +1,11 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+type AuditController interface {
+	FindAll(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	VerifyChain(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"golang_jwt/helper"
+	"golang_jwt/model/web"
+	"golang_jwt/service"
+	"net/http"
+)
+
+type auditControllerImpl struct {
+	AuditService service.AuditService
+}
+
+func NewAuditController(auditService service.AuditService) AuditController {
+	return &auditControllerImpl{
+		AuditService: auditService,
+	}
+}
+
+func (controller *auditControllerImpl) FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	query := request.URL.Query()
+	auditEventQueryRequest := web.AuditEventQueryRequest{
+		Type:      query.Get("type"),
+		Outcome:   query.Get("outcome"),
+		ActorId:   query.Get("actor_id"),
+		UserId:    query.Get("user_id"),
+		SessionId: query.Get("session_id"),
+		IPAddress: query.Get("ip_address"),
+		From:      query.Get("from"),
+		To:        query.Get("to"),
+		BeforeId:  query.Get("before_id"),
+		Limit:     query.Get("limit"),
+	}
+
+	auditEventResponses := controller.AuditService.FindAll(request.Context(), auditEventQueryRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   auditEventResponses,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *auditControllerImpl) VerifyChain(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	verificationResponse := controller.AuditService.VerifyChain(request.Context())
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   verificationResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
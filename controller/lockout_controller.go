@@ -0,0 +1,11 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+type LockoutController interface {
+	UnlockAccount(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	RedeemUnlockToken(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"golang_jwt/helper"
+	"golang_jwt/model/web"
+	"golang_jwt/service"
+	"net/http"
+	"strconv"
+)
+
+type lockoutControllerImpl struct {
+	LoginLockoutService service.LoginLockoutService
+}
+
+func NewLockoutController(loginLockoutService service.LoginLockoutService) LockoutController {
+	return &lockoutControllerImpl{
+		LoginLockoutService: loginLockoutService,
+	}
+}
+
+func (controller *lockoutControllerImpl) UnlockAccount(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userId, err := strconv.Atoi(params.ByName("userId"))
+	helper.ErrorConditionCheck(err)
+
+	controller.LoginLockoutService.UnlockAccount(request.Context(), userId)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   "Account unlocked",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *lockoutControllerImpl) RedeemUnlockToken(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	unlockAccountRequest := web.UnlockAccountRequest{}
+	helper.ReadFromRequestBody(request, &unlockAccountRequest)
+
+	controller.LoginLockoutService.RedeemUnlockToken(request.Context(), unlockAccountRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   "Account unlocked",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
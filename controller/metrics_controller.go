@@ -0,0 +1,10 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+type MetricsController interface {
+	PasswordHashing(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+}
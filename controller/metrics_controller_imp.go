@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"golang_jwt/hasher"
+	"golang_jwt/helper"
+	"golang_jwt/model/web"
+	"net/http"
+)
+
+type metricsControllerImpl struct {
+	PasswordHashPool *hasher.WorkerPool
+}
+
+func NewMetricsController(passwordHashPool *hasher.WorkerPool) MetricsController {
+	return &metricsControllerImpl{
+		PasswordHashPool: passwordHashPool,
+	}
+}
+
+func (controller *metricsControllerImpl) PasswordHashing(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   helper.ToPasswordHashingMetricsResponse(controller.PasswordHashPool.Metrics()),
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
@@ -0,0 +1,15 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+type MfaController interface {
+	EnrollTotp(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	ConfirmTotp(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	DisableTotp(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	CompleteLogin(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	RecoveryCodeStatus(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+}
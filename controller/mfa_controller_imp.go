@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"golang_jwt/helper"
+	"golang_jwt/middleware"
+	"golang_jwt/model/web"
+	"golang_jwt/service"
+	"net/http"
+)
+
+type mfaControllerImpl struct {
+	MfaService   service.MfaService
+	CookieConfig middleware.CookieConfig
+}
+
+func NewMfaController(mfaService service.MfaService, cookieConfig middleware.CookieConfig) MfaController {
+	return &mfaControllerImpl{
+		MfaService:   mfaService,
+		CookieConfig: cookieConfig,
+	}
+}
+
+func (controller *mfaControllerImpl) EnrollTotp(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	claims := request.Context().Value(middleware.UserClaimsKey).(*web.UserClaims)
+
+	totpEnrollmentResponse := controller.MfaService.EnrollTotp(request.Context(), claims.ID)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   totpEnrollmentResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *mfaControllerImpl) ConfirmTotp(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	claims := request.Context().Value(middleware.UserClaimsKey).(*web.UserClaims)
+
+	totpCodeRequest := web.TotpCodeRequest{}
+	helper.ReadFromRequestBody(request, &totpCodeRequest)
+
+	recoveryCodesResponse := controller.MfaService.ConfirmTotp(request.Context(), claims.ID, totpCodeRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   recoveryCodesResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *mfaControllerImpl) DisableTotp(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	claims := request.Context().Value(middleware.UserClaimsKey).(*web.UserClaims)
+
+	totpCodeRequest := web.TotpCodeRequest{}
+	helper.ReadFromRequestBody(request, &totpCodeRequest)
+
+	controller.MfaService.DisableTotp(request.Context(), claims.ID, totpCodeRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   "Two-factor authentication disabled",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *mfaControllerImpl) CompleteLogin(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	mfaLoginRequest := web.MfaLoginRequest{}
+	helper.ReadFromRequestBody(request, &mfaLoginRequest)
+
+	userLoginResponse := controller.MfaService.CompleteLogin(request.Context(), mfaLoginRequest)
+	setSessionCookies(writer, controller.CookieConfig, &userLoginResponse)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   userLoginResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *mfaControllerImpl) RegenerateRecoveryCodes(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	claims := request.Context().Value(middleware.UserClaimsKey).(*web.UserClaims)
+
+	totpCodeRequest := web.TotpCodeRequest{}
+	helper.ReadFromRequestBody(request, &totpCodeRequest)
+
+	recoveryCodesResponse := controller.MfaService.RegenerateRecoveryCodes(request.Context(), claims.ID, totpCodeRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   recoveryCodesResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *mfaControllerImpl) RecoveryCodeStatus(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userId := userIdFromParams(request, params)
+
+	recoveryCodeStatusResponse := controller.MfaService.RecoveryCodeStatus(request.Context(), userId)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   recoveryCodeStatusResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
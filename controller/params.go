@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"golang_jwt/exception"
+	"golang_jwt/middleware"
+	"golang_jwt/model/domain"
+	"golang_jwt/model/web"
+	"net/http"
+	"strconv"
+)
+
+// userIdFromParams resolves the :userId route parameter. "me" always refers
+// to the caller; a numeric id is only accepted for the caller or an admin.
+// GET routes use this form because httprouter cannot register a static
+// /api/users/me next to /api/users/:userId.
+func userIdFromParams(request *http.Request, params httprouter.Params) int {
+	claims := request.Context().Value(middleware.UserClaimsKey).(*web.UserClaims)
+
+	value := params.ByName("userId")
+	if value == "me" {
+		return claims.ID
+	}
+
+	userId, err := strconv.Atoi(value)
+	if err != nil || (userId != claims.ID && claims.Role != domain.RoleAdmin) {
+		panic(exception.NewNotFoundError("user not found"))
+	}
+	return userId
+}
@@ -0,0 +1,15 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+type PasskeyController interface {
+	BeginRegistration(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	FinishRegistration(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	FindAll(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	Delete(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	BeginLogin(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	FinishLogin(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"golang_jwt/helper"
+	"golang_jwt/middleware"
+	"golang_jwt/model/web"
+	"golang_jwt/service"
+	"net/http"
+)
+
+type passkeyControllerImpl struct {
+	PasskeyService service.PasskeyService
+	CookieConfig   middleware.CookieConfig
+}
+
+func NewPasskeyController(passkeyService service.PasskeyService, cookieConfig middleware.CookieConfig) PasskeyController {
+	return &passkeyControllerImpl{
+		PasskeyService: passkeyService,
+		CookieConfig:   cookieConfig,
+	}
+}
+
+func (controller *passkeyControllerImpl) BeginRegistration(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	claims := request.Context().Value(middleware.UserClaimsKey).(*web.UserClaims)
+
+	registrationOptionsResponse := controller.PasskeyService.BeginRegistration(request.Context(), claims.ID)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   registrationOptionsResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *passkeyControllerImpl) FinishRegistration(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	claims := request.Context().Value(middleware.UserClaimsKey).(*web.UserClaims)
+
+	registrationRequest := web.PasskeyRegistrationRequest{}
+	helper.ReadFromRequestBody(request, &registrationRequest)
+
+	passkeyResponse := controller.PasskeyService.FinishRegistration(request.Context(), claims.ID, registrationRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   passkeyResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *passkeyControllerImpl) FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userId := userIdFromParams(request, params)
+
+	passkeyResponses := controller.PasskeyService.FindAll(request.Context(), userId)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   passkeyResponses,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *passkeyControllerImpl) Delete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	claims := request.Context().Value(middleware.UserClaimsKey).(*web.UserClaims)
+
+	controller.PasskeyService.Delete(request.Context(), claims.ID, params.ByName("credentialId"))
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   "Passkey removed",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *passkeyControllerImpl) BeginLogin(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	loginOptionsRequest := web.PasskeyLoginOptionsRequest{}
+	helper.ReadFromRequestBody(request, &loginOptionsRequest)
+
+	loginOptionsResponse := controller.PasskeyService.BeginLogin(request.Context(), loginOptionsRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   loginOptionsResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *passkeyControllerImpl) FinishLogin(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	loginRequest := web.PasskeyLoginRequest{}
+	helper.ReadFromRequestBody(request, &loginRequest)
+
+	userLoginResponse := controller.PasskeyService.FinishLogin(request.Context(), loginRequest)
+	setSessionCookies(writer, controller.CookieConfig, &userLoginResponse)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   userLoginResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
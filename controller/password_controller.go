@@ -0,0 +1,12 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+type PasswordController interface {
+	ChangePassword(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	ForgotPassword(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	ResetPassword(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+}
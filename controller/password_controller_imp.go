@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"golang_jwt/helper"
+	"golang_jwt/middleware"
+	"golang_jwt/model/web"
+	"golang_jwt/service"
+	"net/http"
+)
+
+type passwordControllerImpl struct {
+	PasswordService service.PasswordService
+}
+
+func NewPasswordController(passwordService service.PasswordService) PasswordController {
+	return &passwordControllerImpl{
+		PasswordService: passwordService,
+	}
+}
+
+func (controller *passwordControllerImpl) ChangePassword(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	claims := request.Context().Value(middleware.UserClaimsKey).(*web.UserClaims)
+
+	changePasswordRequest := web.ChangePasswordRequest{}
+	helper.ReadFromRequestBody(request, &changePasswordRequest)
+
+	controller.PasswordService.ChangePassword(request.Context(), claims.ID, changePasswordRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   "Password changed",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *passwordControllerImpl) ForgotPassword(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	forgotPasswordRequest := web.ForgotPasswordRequest{}
+	helper.ReadFromRequestBody(request, &forgotPasswordRequest)
+
+	controller.PasswordService.ForgotPassword(request.Context(), forgotPasswordRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   "If the email is registered, a reset link has been sent",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *passwordControllerImpl) ResetPassword(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	resetPasswordRequest := web.ResetPasswordRequest{}
+	helper.ReadFromRequestBody(request, &resetPasswordRequest)
+
+	controller.PasswordService.ResetPassword(request.Context(), resetPasswordRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   "Password has been reset",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
@@ -0,0 +1,11 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+type PasswordlessController interface {
+	RequestLogin(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	VerifyLogin(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+}
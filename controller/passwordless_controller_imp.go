@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"golang_jwt/helper"
+	"golang_jwt/middleware"
+	"golang_jwt/model/web"
+	"golang_jwt/service"
+	"net/http"
+)
+
+type passwordlessControllerImpl struct {
+	PasswordlessService service.PasswordlessService
+	CookieConfig        middleware.CookieConfig
+}
+
+func NewPasswordlessController(passwordlessService service.PasswordlessService, cookieConfig middleware.CookieConfig) PasswordlessController {
+	return &passwordlessControllerImpl{
+		PasswordlessService: passwordlessService,
+		CookieConfig:        cookieConfig,
+	}
+}
+
+func (controller *passwordlessControllerImpl) RequestLogin(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	passwordlessLoginRequest := web.PasswordlessLoginRequest{}
+	helper.ReadFromRequestBody(request, &passwordlessLoginRequest)
+
+	controller.PasswordlessService.RequestLogin(request.Context(), passwordlessLoginRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   "If the email is registered, a sign-in link or code has been sent",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *passwordlessControllerImpl) VerifyLogin(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	passwordlessVerifyRequest := web.PasswordlessVerifyRequest{}
+	helper.ReadFromRequestBody(request, &passwordlessVerifyRequest)
+
+	userLoginResponse := controller.PasswordlessService.VerifyLogin(request.Context(), passwordlessVerifyRequest)
+	setSessionCookies(writer, controller.CookieConfig, &userLoginResponse)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   userLoginResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
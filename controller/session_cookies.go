@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"golang_jwt/helper"
+	"golang_jwt/middleware"
+	"golang_jwt/model/web"
+	"net/http"
+	"time"
+)
+
+// The refresh token cookie is only sent to the endpoints that use it.
+const refreshTokenCookiePath = "/api/users"
+
+// setSessionCookies moves the issued tokens into cookies when cookie mode is
+// enabled, so they never reach JavaScript. MFA challenges are left alone.
+func setSessionCookies(writer http.ResponseWriter, config middleware.CookieConfig, response *web.UserLoginResponse) {
+	if !config.Enabled || response.RefreshToken == "" {
+		return
+	}
+
+	setCookie(writer, config, middleware.RefreshTokenCookie, response.RefreshToken, refreshTokenCookiePath, *response.RefreshTokenExpiresAt, true)
+	response.RefreshToken = ""
+	if config.AccessToken {
+		setCookie(writer, config, middleware.AccessTokenCookie, response.AccessToken, "/", *response.AccessTokenExpiresAt, true)
+		response.AccessToken = ""
+	}
+	setCookie(writer, config, middleware.CsrfTokenCookie, helper.GenerateRandomToken(32), "/", *response.SessionExpiresAt, false)
+}
+
+// setRenewedCookies replaces the access token cookie and moves the refresh
+// and CSRF cookie expiry along with the session's idle deadline.
+func setRenewedCookies(writer http.ResponseWriter, request *http.Request, config middleware.CookieConfig, refreshToken string, response *web.RenewAccessTokenResponse) {
+	if !config.Enabled || response.RefreshTokenExpiresAt == nil {
+		return
+	}
+
+	setCookie(writer, config, middleware.RefreshTokenCookie, refreshToken, refreshTokenCookiePath, *response.RefreshTokenExpiresAt, true)
+	if config.AccessToken {
+		setCookie(writer, config, middleware.AccessTokenCookie, response.AccessToken, "/", response.AccessTokenExpiresAt, true)
+		response.AccessToken = ""
+	}
+
+	csrfToken := helper.GenerateRandomToken(32)
+	if cookie, err := request.Cookie(middleware.CsrfTokenCookie); err == nil && cookie.Value != "" {
+		csrfToken = cookie.Value
+	}
+	setCookie(writer, config, middleware.CsrfTokenCookie, csrfToken, "/", *response.SessionExpiresAt, false)
+}
+
+// setReauthenticatedCookies replaces the access token cookie with the step-up
+// token. The session and its refresh and CSRF cookies stay as they are.
+func setReauthenticatedCookies(writer http.ResponseWriter, config middleware.CookieConfig, response *web.RenewAccessTokenResponse) {
+	if !config.Enabled || !config.AccessToken {
+		return
+	}
+
+	setCookie(writer, config, middleware.AccessTokenCookie, response.AccessToken, "/", response.AccessTokenExpiresAt, true)
+	response.AccessToken = ""
+}
+
+func clearSessionCookies(writer http.ResponseWriter, config middleware.CookieConfig) {
+	if !config.Enabled {
+		return
+	}
+
+	setCookie(writer, config, middleware.RefreshTokenCookie, "", refreshTokenCookiePath, time.Unix(0, 0), true)
+	setCookie(writer, config, middleware.AccessTokenCookie, "", "/", time.Unix(0, 0), true)
+	setCookie(writer, config, middleware.CsrfTokenCookie, "", "/", time.Unix(0, 0), false)
+}
+
+// refreshTokenFromCookie is used when the request body does not name the
+// refresh token.
+func refreshTokenFromCookie(request *http.Request, config middleware.CookieConfig) string {
+	if !config.Enabled {
+		return ""
+	}
+
+	cookie, err := request.Cookie(middleware.RefreshTokenCookie)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// The CSRF cookie is readable by JavaScript on purpose: the client repeats
+// it in the X-CSRF-Token header.
+func setCookie(writer http.ResponseWriter, config middleware.CookieConfig, name string, value string, path string, expires time.Time, httpOnly bool) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   config.Domain,
+		Expires:  expires,
+		HttpOnly: httpOnly,
+		Secure:   config.Secure,
+		SameSite: config.SameSite,
+	}
+	if value == "" {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(writer, cookie)
+}
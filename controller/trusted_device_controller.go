@@ -0,0 +1,11 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+type TrustedDeviceController interface {
+	FindAll(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	Revoke(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+}
@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"golang_jwt/helper"
+	"golang_jwt/middleware"
+	"golang_jwt/model/web"
+	"golang_jwt/service"
+	"net/http"
+)
+
+type trustedDeviceControllerImpl struct {
+	TrustedDeviceService service.TrustedDeviceService
+}
+
+func NewTrustedDeviceController(trustedDeviceService service.TrustedDeviceService) TrustedDeviceController {
+	return &trustedDeviceControllerImpl{
+		TrustedDeviceService: trustedDeviceService,
+	}
+}
+
+func (controller *trustedDeviceControllerImpl) FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userId := userIdFromParams(request, params)
+
+	trustedDeviceResponses := controller.TrustedDeviceService.FindAll(request.Context(), userId)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   trustedDeviceResponses,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *trustedDeviceControllerImpl) Revoke(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	claims := request.Context().Value(middleware.UserClaimsKey).(*web.UserClaims)
+
+	controller.TrustedDeviceService.Revoke(request.Context(), claims.ID, params.ByName("deviceId"))
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   "Trusted device revoked",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
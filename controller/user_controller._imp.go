@@ -4,18 +4,20 @@ import (
 	"github.com/julienschmidt/httprouter"
 	"net/http"
 	"golang_jwt/helper"
+	"golang_jwt/middleware"
 	"golang_jwt/model/web"
 	"golang_jwt/service"
-	"strconv"
 )
 
 type userControllerImpl struct {
 	UserService service.UserService
+	CookieConfig middleware.CookieConfig
 }
 
-func NewUserController(userService service.UserService) UserController {
+func NewUserController(userService service.UserService, cookieConfig middleware.CookieConfig) UserController {
 	return &userControllerImpl{
 		UserService: userService,
+		CookieConfig: cookieConfig,
 	}
 }
 
@@ -23,11 +25,11 @@ func (controller *userControllerImpl) Register(writer http.ResponseWriter, reque
 	userCreateRequest := web.UserCreateRequest{}
 	helper.ReadFromRequestBody(request, &userCreateRequest)
 
-	userResponse := controller.UserService.Register(request.Context(), userCreateRequest)
+	controller.UserService.Register(request.Context(), userCreateRequest)
 	webResponse := web.WebResponse{
 		Code:   200,
 		Status: "OK",
-		Data:   userResponse,
+		Data:   "Registration received, please check your email",
 	}
 
 	helper.WriteToResponseBody(writer, webResponse)
@@ -38,6 +40,7 @@ func (controller *userControllerImpl) Login(writer http.ResponseWriter, request
 	helper.ReadFromRequestBody(request, &userLoginRequest)
 
 	userLoginResponse := controller.UserService.Login(request.Context(), userLoginRequest)
+	setSessionCookies(writer, controller.CookieConfig, &userLoginResponse)
 	webResponse := web.WebResponse{
 		Code:   200,
 		Status: "OK",
@@ -48,9 +51,18 @@ func (controller *userControllerImpl) Login(writer http.ResponseWriter, request
 }
 
 func (controller *userControllerImpl) Logout(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	sessionId := params.ByName("sessionId")
-	controller.UserService.Logout(request.Context(), sessionId)
-	
+	claims := request.Context().Value(middleware.UserClaimsKey).(*web.UserClaims)
+
+	logoutRequest := web.LogoutRequest{}
+	if request.ContentLength != 0 {
+		helper.ReadFromRequestBody(request, &logoutRequest)
+	}
+	if logoutRequest.RefreshToken == "" {
+		logoutRequest.RefreshToken = refreshTokenFromCookie(request, controller.CookieConfig)
+	}
+
+	controller.UserService.Logout(request.Context(), claims, logoutRequest)
+	clearSessionCookies(writer, controller.CookieConfig)
 	webResponse := web.WebResponse{
 		Code:   200,
 		Status: "OK",
@@ -62,9 +74,15 @@ func (controller *userControllerImpl) Logout(writer http.ResponseWriter, request
 
 func (controller *userControllerImpl) RenewAccessToken(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	renewAccessTokenRequest := web.RenewAccessTokenRequest{}
-	helper.ReadFromRequestBody(request, &renewAccessTokenRequest)
+	if request.ContentLength != 0 {
+		helper.ReadFromRequestBody(request, &renewAccessTokenRequest)
+	}
+	if renewAccessTokenRequest.RefreshToken == "" {
+		renewAccessTokenRequest.RefreshToken = refreshTokenFromCookie(request, controller.CookieConfig)
+	}
 
 	renewAccessTokenResponse := controller.UserService.RenewAccessToken(request.Context(), renewAccessTokenRequest)
+	setRenewedCookies(writer, request, controller.CookieConfig, renewAccessTokenRequest.RefreshToken, &renewAccessTokenResponse)
 	webResponse := web.WebResponse{
 		Code:   200,
 		Status: "OK",
@@ -74,10 +92,94 @@ func (controller *userControllerImpl) RenewAccessToken(writer http.ResponseWrite
 	helper.WriteToResponseBody(writer, webResponse)
 }
 
+// RevokeSession takes the session id from the admin route parameter or,
+// on the user route, from the optional request body.
 func (controller *userControllerImpl) RevokeSession(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	sessionId := params.ByName("sessionId")
-	controller.UserService.RevokeSession(request.Context(), sessionId)
-	
+	claims := request.Context().Value(middleware.UserClaimsKey).(*web.UserClaims)
+
+	revokeSessionRequest := web.RevokeSessionRequest{SessionId: params.ByName("sessionId")}
+	if revokeSessionRequest.SessionId == "" && request.ContentLength != 0 {
+		helper.ReadFromRequestBody(request, &revokeSessionRequest)
+	}
+
+	controller.UserService.RevokeSession(request.Context(), claims, revokeSessionRequest)
+	webResponse := web.WebResponse{
+		Code: 200,
+		Status: "OK",
+		Data:   "Session revoked",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *userControllerImpl) Reauthenticate(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	claims := request.Context().Value(middleware.UserClaimsKey).(*web.UserClaims)
+
+	reauthenticateRequest := web.ReauthenticateRequest{}
+	helper.ReadFromRequestBody(request, &reauthenticateRequest)
+
+	renewAccessTokenResponse := controller.UserService.Reauthenticate(request.Context(), claims, reauthenticateRequest)
+	setReauthenticatedCookies(writer, controller.CookieConfig, &renewAccessTokenResponse)
+	webResponse := web.WebResponse{
+		Code: 200,
+		Status: "OK",
+		Data:   renewAccessTokenResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *userControllerImpl) DeleteAccount(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	claims := request.Context().Value(middleware.UserClaimsKey).(*web.UserClaims)
+
+	controller.UserService.DeleteAccount(request.Context(), claims.ID)
+	webResponse := web.WebResponse{
+		Code: 200,
+		Status: "OK",
+		Data:   "Account deleted",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+// LogoutAll serves both the caller's own route and the admin route, which
+// names the user in the path.
+func (controller *userControllerImpl) LogoutAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	claims := request.Context().Value(middleware.UserClaimsKey).(*web.UserClaims)
+
+	userId := claims.ID
+	if params.ByName("userId") != "" {
+		userId = userIdFromParams(request, params)
+	}
+
+	controller.UserService.LogoutAll(request.Context(), userId)
+	webResponse := web.WebResponse{
+		Code: 200,
+		Status: "OK",
+		Data:   "Logged out from all devices",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *userControllerImpl) FindSessions(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	claims := request.Context().Value(middleware.UserClaimsKey).(*web.UserClaims)
+	userId := userIdFromParams(request, params)
+
+	sessionResponses := controller.UserService.FindSessions(request.Context(), userId, claims.SessionID)
+	webResponse := web.WebResponse{
+		Code: 200,
+		Status: "OK",
+		Data:   sessionResponses,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *userControllerImpl) DeleteSession(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	claims := request.Context().Value(middleware.UserClaimsKey).(*web.UserClaims)
+
+	controller.UserService.DeleteSession(request.Context(), claims.ID, params.ByName("sessionId"))
 	webResponse := web.WebResponse{
 		Code: 200,
 		Status: "OK",
@@ -88,8 +190,7 @@ func (controller *userControllerImpl) RevokeSession(writer http.ResponseWriter,
 }
 
 func (controller *userControllerImpl) FindById(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	userId, err := strconv.Atoi(params.ByName("userId"))
-	helper.ErrorConditionCheck(err)
+	userId := userIdFromParams(request, params)
 
 	userResponse := controller.UserService.FindById(request.Context(), userId)
 	webResponse := web.WebResponse{
@@ -11,6 +11,11 @@ type UserController interface {
 	Logout(w http.ResponseWriter, r *http.Request, params httprouter.Params)
 	RenewAccessToken(w http.ResponseWriter, r *http.Request, params httprouter.Params)
 	RevokeSession(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	Reauthenticate(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	DeleteAccount(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	LogoutAll(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	FindSessions(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	DeleteSession(w http.ResponseWriter, r *http.Request, params httprouter.Params)
 	FindById(w http.ResponseWriter, r *http.Request, params httprouter.Params)
 	FindAll(w http.ResponseWriter, r *http.Request, params httprouter.Params)
 }
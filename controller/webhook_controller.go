@@ -0,0 +1,15 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+type WebhookController interface {
+	Create(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	FindAll(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	Delete(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	FindDeliveries(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	FindDeadLetters(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	Redeliver(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"golang_jwt/exception"
+	"golang_jwt/helper"
+	"golang_jwt/model/domain"
+	"golang_jwt/model/web"
+	"golang_jwt/service"
+	"net/http"
+	"strconv"
+)
+
+type webhookControllerImpl struct {
+	WebhookService service.WebhookService
+}
+
+func NewWebhookController(webhookService service.WebhookService) WebhookController {
+	return &webhookControllerImpl{
+		WebhookService: webhookService,
+	}
+}
+
+func (controller *webhookControllerImpl) Create(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	webhookEndpointCreateRequest := web.WebhookEndpointCreateRequest{}
+	helper.ReadFromRequestBody(request, &webhookEndpointCreateRequest)
+
+	webhookEndpointResponse := controller.WebhookService.CreateEndpoint(request.Context(), webhookEndpointCreateRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   webhookEndpointResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *webhookControllerImpl) FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	webhookEndpointResponses := controller.WebhookService.FindEndpoints(request.Context())
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   webhookEndpointResponses,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *webhookControllerImpl) Delete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	webhookId, err := strconv.ParseInt(params.ByName("webhookId"), 10, 64)
+	if err != nil {
+		panic(exception.NewNotFoundError("webhook not found"))
+	}
+
+	controller.WebhookService.DeleteEndpoint(request.Context(), webhookId)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   "Webhook deleted",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *webhookControllerImpl) FindDeliveries(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	webhookDeliveryQueryRequest := webhookDeliveryQuery(request)
+
+	controller.writeDeliveries(writer, request, webhookDeliveryQueryRequest)
+}
+
+// FindDeadLetters lists the deliveries that ran out of attempts.
+func (controller *webhookControllerImpl) FindDeadLetters(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	webhookDeliveryQueryRequest := webhookDeliveryQuery(request)
+	webhookDeliveryQueryRequest.Status = domain.WebhookDeliveryDead
+
+	controller.writeDeliveries(writer, request, webhookDeliveryQueryRequest)
+}
+
+func (controller *webhookControllerImpl) Redeliver(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	deliveryId, err := strconv.ParseInt(params.ByName("deliveryId"), 10, 64)
+	if err != nil {
+		panic(exception.NewNotFoundError("webhook delivery not found"))
+	}
+
+	webhookDeliveryResponse := controller.WebhookService.Redeliver(request.Context(), deliveryId)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   webhookDeliveryResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *webhookControllerImpl) writeDeliveries(writer http.ResponseWriter, request *http.Request, webhookDeliveryQueryRequest web.WebhookDeliveryQueryRequest) {
+	webhookDeliveryResponses := controller.WebhookService.FindDeliveries(request.Context(), webhookDeliveryQueryRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   webhookDeliveryResponses,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func webhookDeliveryQuery(request *http.Request) web.WebhookDeliveryQueryRequest {
+	query := request.URL.Query()
+	return web.WebhookDeliveryQueryRequest{
+		WebhookId: query.Get("webhook_id"),
+		Status:    query.Get("status"),
+		EventType: query.Get("event_type"),
+		BeforeId:  query.Get("before_id"),
+		Limit:     query.Get("limit"),
+	}
+}
@@ -0,0 +1,119 @@
+package dpop
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"math/big"
+)
+
+// JSONWebKey holds the public members of a JWK (RFC 7517) for the key types
+// accepted in proofs: EC P-256, RSA and Ed25519.
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	D   string `json:"d,omitempty"`
+}
+
+// ParseJSONWebKey reads the jwk header of a proof. Keys with private
+// members are refused.
+func ParseJSONWebKey(header interface{}) (JSONWebKey, crypto.PublicKey, error) {
+	encoded, err := json.Marshal(header)
+	if err != nil {
+		return JSONWebKey{}, nil, ErrUnsupportedKey
+	}
+
+	key := JSONWebKey{}
+	if err := json.Unmarshal(encoded, &key); err != nil || key.D != "" {
+		return JSONWebKey{}, nil, ErrUnsupportedKey
+	}
+
+	publicKey, err := key.publicKey()
+	if err != nil {
+		return JSONWebKey{}, nil, err
+	}
+	return key, publicKey, nil
+}
+
+func (key JSONWebKey) publicKey() (crypto.PublicKey, error) {
+	switch {
+	case key.Kty == "EC" && key.Crv == "P-256":
+		x, errX := Encoding.DecodeString(key.X)
+		y, errY := Encoding.DecodeString(key.Y)
+		if errX != nil || errY != nil || len(x) != 32 || len(y) != 32 {
+			return nil, ErrUnsupportedKey
+		}
+
+		// ecdh rejects points that are not on the curve.
+		_, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...))
+		if err != nil {
+			return nil, ErrUnsupportedKey
+		}
+
+		return &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+
+	case key.Kty == "OKP" && key.Crv == "Ed25519":
+		x, err := Encoding.DecodeString(key.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, ErrUnsupportedKey
+		}
+		return ed25519.PublicKey(x), nil
+
+	case key.Kty == "RSA":
+		n, errN := Encoding.DecodeString(key.N)
+		e, errE := Encoding.DecodeString(key.E)
+		if errN != nil || errE != nil || len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, ErrUnsupportedKey
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	}
+
+	return nil, ErrUnsupportedKey
+}
+
+// Thumbprint is the RFC 7638 SHA-256 thumbprint used as the cnf.jkt claim.
+// Only the required members take part, in lexicographic order.
+func (key JSONWebKey) Thumbprint() string {
+	var members interface{}
+	switch key.Kty {
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{key.Crv, key.Kty, key.X, key.Y}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{key.Crv, key.Kty, key.X}
+	default:
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{key.E, key.Kty, key.N}
+	}
+
+	encoded, _ := json.Marshal(members)
+	digest := sha256.Sum256(encoded)
+	return Encoding.EncodeToString(digest[:])
+}
@@ -0,0 +1,170 @@
+package dpop
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	HeaderName = "DPoP"
+	proofType  = "dpop+jwt"
+)
+
+// Algorithms lists the proof signature algorithms, as advertised in
+// WWW-Authenticate challenges.
+var Algorithms = []string{"ES256", "EdDSA", "RS256", "PS256"}
+
+var (
+	ErrInvalidProof   = errors.New("dpop: invalid proof")
+	ErrUnsupportedKey = errors.New("dpop: unsupported proof key")
+	ErrMethodMismatch = errors.New("dpop: htm does not match the request method")
+	ErrURLMismatch    = errors.New("dpop: htu does not match the request URL")
+	ErrStaleProof     = errors.New("dpop: proof is not fresh")
+	ErrReplayedProof  = errors.New("dpop: proof has already been used")
+	ErrTokenMismatch  = errors.New("dpop: ath does not match the access token")
+)
+
+var Encoding = base64.RawURLEncoding
+
+// Config controls proof validation. MaxAge bounds how far iat may be from
+// the server clock in either direction. BaseURL, when set, is the public
+// origin used to rebuild htu behind a proxy.
+type Config struct {
+	MaxAge  time.Duration
+	BaseURL string
+}
+
+type Verifier struct {
+	Config      Config
+	ReplayCache ReplayCache
+}
+
+func NewVerifier(config Config, replayCache ReplayCache) *Verifier {
+	return &Verifier{
+		Config:      config,
+		ReplayCache: replayCache,
+	}
+}
+
+type proofClaims struct {
+	Htm string `json:"htm"`
+	Htu string `json:"htu"`
+	Ath string `json:"ath,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Verify checks a proof (RFC 9449, section 4.3) for the given request and
+// returns the thumbprint of its key. accessToken is empty at the token
+// endpoints; otherwise the proof must carry its hash in ath.
+func (verifier *Verifier) Verify(proof string, method string, requestURL string, accessToken string) (string, error) {
+	var key JSONWebKey
+	token, err := jwt.ParseWithClaims(proof, &proofClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if typ, _ := token.Header["typ"].(string); typ != proofType {
+			return nil, ErrInvalidProof
+		}
+
+		parsedKey, publicKey, err := ParseJSONWebKey(token.Header["jwk"])
+		if err != nil {
+			return nil, err
+		}
+		key = parsedKey
+		return publicKey, nil
+	}, jwt.WithValidMethods(Algorithms))
+	if err != nil {
+		return "", ErrInvalidProof
+	}
+
+	claims, ok := token.Claims.(*proofClaims)
+	if !ok || claims.ID == "" || claims.IssuedAt == nil {
+		return "", ErrInvalidProof
+	}
+
+	if claims.Htm != method {
+		return "", ErrMethodMismatch
+	}
+	if normalizeURL(claims.Htu) == "" || normalizeURL(claims.Htu) != normalizeURL(requestURL) {
+		return "", ErrURLMismatch
+	}
+
+	issuedAt := claims.IssuedAt.Time
+	if time.Since(issuedAt) > verifier.Config.MaxAge || time.Until(issuedAt) > verifier.Config.MaxAge {
+		return "", ErrStaleProof
+	}
+
+	if accessToken != "" {
+		digest := sha256.Sum256([]byte(accessToken))
+		if subtle.ConstantTimeCompare([]byte(claims.Ath), []byte(Encoding.EncodeToString(digest[:]))) != 1 {
+			return "", ErrTokenMismatch
+		}
+	}
+
+	thumbprint := key.Thumbprint()
+	if !verifier.ReplayCache.Remember(thumbprint+":"+claims.ID, issuedAt.Add(verifier.Config.MaxAge)) {
+		return "", ErrReplayedProof
+	}
+
+	return thumbprint, nil
+}
+
+// RequestURL is the htu a client is expected to sign for request.
+func (verifier *Verifier) RequestURL(request *http.Request) string {
+	if verifier.Config.BaseURL != "" {
+		return strings.TrimRight(verifier.Config.BaseURL, "/") + request.URL.Path
+	}
+
+	scheme := "http"
+	if request.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + request.Host + request.URL.Path
+}
+
+// normalizeURL drops the query and fragment and applies the syntax-based
+// normalization of RFC 3986, as htu is compared without them.
+func normalizeURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return ""
+	}
+
+	scheme := strings.ToLower(parsed.Scheme)
+	host := strings.ToLower(parsed.Hostname())
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	port := parsed.Port()
+	if (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		port = ""
+	}
+	if port != "" {
+		host += ":" + port
+	}
+
+	path := parsed.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	return scheme + "://" + host + path
+}
+
+type thumbprintKey struct{}
+
+// WithThumbprint records the key of a verified proof for the service layer,
+// which binds newly issued tokens to it.
+func WithThumbprint(ctx context.Context, thumbprint string) context.Context {
+	return context.WithValue(ctx, thumbprintKey{}, thumbprint)
+}
+
+func ThumbprintFromContext(ctx context.Context) string {
+	thumbprint, _ := ctx.Value(thumbprintKey{}).(string)
+	return thumbprint
+}
@@ -0,0 +1,51 @@
+package dpop
+
+import (
+	"sync"
+	"time"
+)
+
+// ReplayCache remembers proof identifiers until the proofs could no longer
+// pass the freshness check anyway.
+type ReplayCache interface {
+	// Remember stores id until expiresAt and reports false when it was
+	// already stored, meaning the proof is a replay.
+	Remember(id string, expiresAt time.Time) bool
+}
+
+type memoryReplayCache struct {
+	mutex     sync.Mutex
+	entries   map[string]time.Time
+	lastSweep time.Time
+}
+
+const sweepInterval = time.Minute
+
+// NewMemoryReplayCache keeps the identifiers in process memory, so replays
+// are only detected within one instance.
+func NewMemoryReplayCache() ReplayCache {
+	return &memoryReplayCache{
+		entries: map[string]time.Time{},
+	}
+}
+
+func (cache *memoryReplayCache) Remember(id string, expiresAt time.Time) bool {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(cache.lastSweep) >= sweepInterval {
+		for entry, entryExpiresAt := range cache.entries {
+			if entryExpiresAt.Before(now) {
+				delete(cache.entries, entry)
+			}
+		}
+		cache.lastSweep = now
+	}
+
+	if entryExpiresAt, ok := cache.entries[id]; ok && entryExpiresAt.After(now) {
+		return false
+	}
+	cache.entries[id] = expiresAt
+	return true
+}
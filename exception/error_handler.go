@@ -2,7 +2,10 @@ package exception
 
 import (
 	"github.com/go-playground/validator/v10"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 	"golang_jwt/helper"
 	"golang_jwt/model/web"
 )
@@ -13,10 +16,34 @@ func ErrorHandler(writer http.ResponseWriter, request *http.Request, err interfa
 		return
 	}
 
+	if unauthorizedError(writer, request, err) {
+		return
+	}
+
+	if forbiddenError(writer, request, err) {
+		return
+	}
+
+	if tooManyRequestsError(writer, request, err) {
+		return
+	}
+
+	if lockedError(writer, request, err) {
+		return
+	}
+
+	if serviceUnavailableError(writer, request, err) {
+		return
+	}
+
 	if validationErrors(writer, request, err) {
 		return
 	}
 
+	if validationError(writer, request, err) {
+		return
+	}
+
 	internalServerError(writer, request, err)
 }
 
@@ -39,6 +66,25 @@ func validationErrors(writer http.ResponseWriter, request *http.Request, err int
 	}
 }
 
+func validationError(writer http.ResponseWriter, request *http.Request, err interface{}) bool {
+	exception, ok := err.(ValidationError)
+	if ok {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "BAD REQUEST",
+			Data:   exception.Errors,
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return true
+	} else {
+		return false
+	}
+}
+
 func notFoundError(writer http.ResponseWriter, request *http.Request, err interface{}) bool {
 	exception, ok := err.(NotFoundError)
 	if ok {
@@ -58,6 +104,112 @@ func notFoundError(writer http.ResponseWriter, request *http.Request, err interf
 	}
 }
 
+func unauthorizedError(writer http.ResponseWriter, request *http.Request, err interface{}) bool {
+	exception, ok := err.(UnauthorizedError)
+	if ok {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusUnauthorized)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusUnauthorized,
+			Status: "UNAUTHORIZED",
+			Data:   exception.Error,
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return true
+	} else {
+		return false
+	}
+}
+
+func forbiddenError(writer http.ResponseWriter, request *http.Request, err interface{}) bool {
+	exception, ok := err.(ForbiddenError)
+	if ok {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusForbidden)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusForbidden,
+			Status: "FORBIDDEN",
+			Data:   exception.Error,
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return true
+	} else {
+		return false
+	}
+}
+
+func tooManyRequestsError(writer http.ResponseWriter, request *http.Request, err interface{}) bool {
+	exception, ok := err.(TooManyRequestsError)
+	if ok {
+		setRetryAfter(writer, exception.RetryAfter)
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusTooManyRequests)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusTooManyRequests,
+			Status: "TOO MANY REQUESTS",
+			Data:   exception.Error,
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return true
+	} else {
+		return false
+	}
+}
+
+func lockedError(writer http.ResponseWriter, request *http.Request, err interface{}) bool {
+	exception, ok := err.(LockedError)
+	if ok {
+		setRetryAfter(writer, exception.RetryAfter)
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusLocked)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusLocked,
+			Status: "LOCKED",
+			Data:   exception.Error,
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return true
+	} else {
+		return false
+	}
+}
+
+func serviceUnavailableError(writer http.ResponseWriter, request *http.Request, err interface{}) bool {
+	exception, ok := err.(ServiceUnavailableError)
+	if ok {
+		setRetryAfter(writer, exception.RetryAfter)
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusServiceUnavailable)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusServiceUnavailable,
+			Status: "SERVICE UNAVAILABLE",
+			Data:   exception.Error,
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return true
+	} else {
+		return false
+	}
+}
+
+func setRetryAfter(writer http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	writer.Header().Set("Retry-After", strconv.Itoa(seconds))
+}
+
 func internalServerError(writer http.ResponseWriter, request *http.Request, err interface{}) {
 	writer.Header().Set("Content-Type", "application/json")
 	writer.WriteHeader(http.StatusInternalServerError)
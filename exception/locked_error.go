@@ -0,0 +1,12 @@
+package exception
+
+import "time"
+
+type LockedError struct {
+	Error      string
+	RetryAfter time.Duration
+}
+
+func NewLockedError(error string, retryAfter time.Duration) LockedError {
+	return LockedError{Error: error, RetryAfter: retryAfter}
+}
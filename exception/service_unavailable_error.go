@@ -0,0 +1,12 @@
+package exception
+
+import "time"
+
+type ServiceUnavailableError struct {
+	Error      string
+	RetryAfter time.Duration
+}
+
+func NewServiceUnavailableError(error string, retryAfter time.Duration) ServiceUnavailableError {
+	return ServiceUnavailableError{Error: error, RetryAfter: retryAfter}
+}
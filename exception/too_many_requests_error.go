@@ -0,0 +1,12 @@
+package exception
+
+import "time"
+
+type TooManyRequestsError struct {
+	Error      string
+	RetryAfter time.Duration
+}
+
+func NewTooManyRequestsError(error string, retryAfter time.Duration) TooManyRequestsError {
+	return TooManyRequestsError{Error: error, RetryAfter: retryAfter}
+}
@@ -0,0 +1,9 @@
+package exception
+
+type UnauthorizedError struct {
+	Error string
+}
+
+func NewUnauthorizedError(error string) UnauthorizedError {
+	return UnauthorizedError{Error: error}
+}
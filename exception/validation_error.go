@@ -0,0 +1,11 @@
+package exception
+
+import "golang_jwt/model/web"
+
+type ValidationError struct {
+	Errors []web.FieldError
+}
+
+func NewValidationError(errors []web.FieldError) ValidationError {
+	return ValidationError{Errors: errors}
+}
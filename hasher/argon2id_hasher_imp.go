@@ -0,0 +1,123 @@
+package hasher
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2idParams holds the tunable cost parameters. Memory is in KiB.
+type Argon2idParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+func DefaultArgon2idParams() Argon2idParams {
+	return Argon2idParams{
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+type Argon2idHasherImpl struct {
+	Params Argon2idParams
+}
+
+func NewArgon2idHasher(params Argon2idParams) PasswordHasher {
+	return &Argon2idHasherImpl{
+		Params: params,
+	}
+}
+
+// Hash encodes the result in PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+func (hasher *Argon2idHasherImpl) Hash(ctx context.Context, password string) (string, error) {
+	salt := make([]byte, hasher.Params.SaltLength)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, hasher.Params.Iterations, hasher.Params.Memory, hasher.Params.Parallelism, hasher.Params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		hasher.Params.Memory,
+		hasher.Params.Iterations,
+		hasher.Params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (hasher *Argon2idHasherImpl) Verify(ctx context.Context, encodedHash string, password string) (bool, error) {
+	params, salt, key, err := decodeArgon2idHash(encodedHash)
+	if err != nil {
+		return false, err
+	}
+
+	otherKey := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+	return subtle.ConstantTimeCompare(key, otherKey) == 1, nil
+}
+
+func (hasher *Argon2idHasherImpl) NeedsRehash(encodedHash string) bool {
+	params, salt, _, err := decodeArgon2idHash(encodedHash)
+	if err != nil {
+		return true
+	}
+
+	return params.Memory != hasher.Params.Memory ||
+		params.Iterations != hasher.Params.Iterations ||
+		params.Parallelism != hasher.Params.Parallelism ||
+		params.KeyLength != hasher.Params.KeyLength ||
+		uint32(len(salt)) != hasher.Params.SaltLength
+}
+
+func decodeArgon2idHash(encodedHash string) (Argon2idParams, []byte, []byte, error) {
+	params := Argon2idParams{}
+
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[1] != AlgorithmArgon2id {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+	if version != argon2.Version {
+		return params, nil, nil, ErrUnsupportedHash
+	}
+
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism)
+	if err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.Strict().DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+	params.SaltLength = uint32(len(salt))
+
+	key, err := base64.RawStdEncoding.Strict().DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+	params.KeyLength = uint32(len(key))
+
+	return params, salt, key, nil
+}
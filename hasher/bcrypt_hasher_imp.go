@@ -0,0 +1,51 @@
+package hasher
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type BcryptHasherImpl struct {
+	Cost int
+}
+
+func NewBcryptHasher(cost int) PasswordHasher {
+	return &BcryptHasherImpl{
+		Cost: cost,
+	}
+}
+
+// Hash fails with bcrypt.ErrPasswordTooLong for passwords longer than 72
+// bytes instead of silently truncating them. The password policy rejects
+// such passwords before they get here (see MaxPasswordBytes).
+func (hasher *BcryptHasherImpl) Hash(ctx context.Context, password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), hasher.Cost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hashedPassword), nil
+}
+
+func (hasher *BcryptHasherImpl) Verify(ctx context.Context, encodedHash string, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (hasher *BcryptHasherImpl) NeedsRehash(encodedHash string) bool {
+	cost, err := bcrypt.Cost([]byte(encodedHash))
+	if err != nil {
+		return true
+	}
+
+	return cost != hasher.Cost
+}
@@ -0,0 +1,40 @@
+package hasher
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+var (
+	ErrInvalidHash     = errors.New("invalid password hash format")
+	ErrUnsupportedHash = errors.New("unsupported password hash algorithm")
+)
+
+// Hash and Verify may give up early when ctx is done, or when the hashing
+// pool is saturated (ErrPoolSaturated).
+type PasswordHasher interface {
+	Hash(ctx context.Context, password string) (string, error)
+	Verify(ctx context.Context, encodedHash string, password string) (bool, error)
+	NeedsRehash(encodedHash string) bool
+}
+
+// MaxPasswordBytes is the longest password the algorithm hashes in full,
+// or 0 when there is no limit. bcrypt only uses the first 72 bytes.
+func MaxPasswordBytes(algorithm string) int {
+	if algorithm == AlgorithmBcrypt {
+		return 72
+	}
+	return 0
+}
+
+type Config struct {
+	Algorithm  string
+	Argon2id   Argon2idParams
+	BcryptCost int
+	Pool       PoolConfig
+}
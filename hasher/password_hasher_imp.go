@@ -0,0 +1,64 @@
+package hasher
+
+import (
+	"context"
+	"strings"
+)
+
+// PasswordHasherImpl hashes new passwords with the configured algorithm and
+// still verifies hashes produced by any supported algorithm, so stored hashes
+// can be upgraded lazily on login.
+type PasswordHasherImpl struct {
+	Algorithm string
+	Hashers   map[string]PasswordHasher
+}
+
+func NewPasswordHasher(config Config) PasswordHasher {
+	algorithm := config.Algorithm
+	if algorithm != AlgorithmBcrypt {
+		algorithm = AlgorithmArgon2id
+	}
+
+	return &PasswordHasherImpl{
+		Algorithm: algorithm,
+		Hashers: map[string]PasswordHasher{
+			AlgorithmArgon2id: NewArgon2idHasher(config.Argon2id),
+			AlgorithmBcrypt:   NewBcryptHasher(config.BcryptCost),
+		},
+	}
+}
+
+func (hasher *PasswordHasherImpl) Hash(ctx context.Context, password string) (string, error) {
+	return hasher.Hashers[hasher.Algorithm].Hash(ctx, password)
+}
+
+func (hasher *PasswordHasherImpl) Verify(ctx context.Context, encodedHash string, password string) (bool, error) {
+	passwordHasher, ok := hasher.Hashers[identifyAlgorithm(encodedHash)]
+	if !ok {
+		return false, ErrUnsupportedHash
+	}
+
+	return passwordHasher.Verify(ctx, encodedHash, password)
+}
+
+func (hasher *PasswordHasherImpl) NeedsRehash(encodedHash string) bool {
+	algorithm := identifyAlgorithm(encodedHash)
+	if algorithm != hasher.Algorithm {
+		return true
+	}
+
+	return hasher.Hashers[algorithm].NeedsRehash(encodedHash)
+}
+
+func identifyAlgorithm(encodedHash string) string {
+	switch {
+	case strings.HasPrefix(encodedHash, "$argon2id$"):
+		return AlgorithmArgon2id
+	case strings.HasPrefix(encodedHash, "$2a$"),
+		strings.HasPrefix(encodedHash, "$2b$"),
+		strings.HasPrefix(encodedHash, "$2y$"):
+		return AlgorithmBcrypt
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,46 @@
+package hasher
+
+import "context"
+
+// PooledPasswordHasherImpl runs the hashing of another PasswordHasher on a
+// WorkerPool, so bursts of logins queue up instead of taking every CPU.
+type PooledPasswordHasherImpl struct {
+	Hasher PasswordHasher
+	Pool   *WorkerPool
+}
+
+func NewPooledPasswordHasher(hasher PasswordHasher, pool *WorkerPool) PasswordHasher {
+	return &PooledPasswordHasherImpl{
+		Hasher: hasher,
+		Pool:   pool,
+	}
+}
+
+func (hasher *PooledPasswordHasherImpl) Hash(ctx context.Context, password string) (string, error) {
+	var encodedHash string
+	var hashErr error
+	err := hasher.Pool.Do(ctx, func() {
+		encodedHash, hashErr = hasher.Hasher.Hash(ctx, password)
+	})
+	if err != nil {
+		return "", err
+	}
+	return encodedHash, hashErr
+}
+
+func (hasher *PooledPasswordHasherImpl) Verify(ctx context.Context, encodedHash string, password string) (bool, error) {
+	var valid bool
+	var verifyErr error
+	err := hasher.Pool.Do(ctx, func() {
+		valid, verifyErr = hasher.Hasher.Verify(ctx, encodedHash, password)
+	})
+	if err != nil {
+		return false, err
+	}
+	return valid, verifyErr
+}
+
+// NeedsRehash only parses the hash, so it does not go through the pool.
+func (hasher *PooledPasswordHasherImpl) NeedsRehash(encodedHash string) bool {
+	return hasher.Hasher.NeedsRehash(encodedHash)
+}
@@ -0,0 +1,134 @@
+package hasher
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+)
+
+var ErrPoolSaturated = errors.New("password hashing pool is saturated")
+
+// PoolConfig bounds the hashing work: at most Workers hashes run at once and
+// at most QueueDepth more wait for a worker. Zero values pick half of the
+// CPUs, leaving the rest for other requests, and a queue of 64.
+type PoolConfig struct {
+	Workers    int
+	QueueDepth int
+}
+
+// PoolMetrics is a snapshot of the pool. Busy and Queued are current
+// values; the others count since startup.
+type PoolMetrics struct {
+	Workers    int
+	QueueDepth int
+	Busy       int64
+	Queued     int64
+	Completed  uint64
+	Rejected   uint64
+	Canceled   uint64
+}
+
+type poolJob struct {
+	ctx      context.Context
+	work     func()
+	done     chan struct{}
+	panicked interface{}
+}
+
+type WorkerPool struct {
+	config    PoolConfig
+	jobs      chan *poolJob
+	busy      atomic.Int64
+	queued    atomic.Int64
+	completed atomic.Uint64
+	rejected  atomic.Uint64
+	canceled  atomic.Uint64
+}
+
+func NewWorkerPool(config PoolConfig) *WorkerPool {
+	if config.Workers <= 0 {
+		config.Workers = max(runtime.NumCPU()/2, 1)
+	}
+	if config.QueueDepth <= 0 {
+		config.QueueDepth = 64
+	}
+
+	pool := &WorkerPool{
+		config: config,
+		jobs:   make(chan *poolJob, config.QueueDepth),
+	}
+	for i := 0; i < config.Workers; i++ {
+		go pool.worker()
+	}
+	return pool
+}
+
+// Do runs work on a pool worker and waits for it. It fails right away with
+// ErrPoolSaturated when the queue is full, and returns ctx.Err() when ctx
+// is done first; queued work for a done ctx is skipped, work that already
+// started runs to completion. A panic in work is raised again here, on the
+// caller's goroutine.
+func (pool *WorkerPool) Do(ctx context.Context, work func()) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	job := &poolJob{ctx: ctx, work: work, done: make(chan struct{})}
+	pool.queued.Add(1)
+	select {
+	case pool.jobs <- job:
+	default:
+		pool.queued.Add(-1)
+		pool.rejected.Add(1)
+		return ErrPoolSaturated
+	}
+
+	select {
+	case <-job.done:
+		if job.panicked != nil {
+			panic(job.panicked)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (pool *WorkerPool) worker() {
+	for job := range pool.jobs {
+		pool.queued.Add(-1)
+		if job.ctx.Err() != nil {
+			pool.canceled.Add(1)
+			continue
+		}
+
+		pool.run(job)
+	}
+}
+
+// run keeps the worker alive when work panics and always releases the
+// waiting caller.
+func (pool *WorkerPool) run(job *poolJob) {
+	pool.busy.Add(1)
+	defer func() {
+		job.panicked = recover()
+		pool.busy.Add(-1)
+		pool.completed.Add(1)
+		close(job.done)
+	}()
+
+	job.work()
+}
+
+func (pool *WorkerPool) Metrics() PoolMetrics {
+	return PoolMetrics{
+		Workers:    pool.config.Workers,
+		QueueDepth: pool.config.QueueDepth,
+		Busy:       pool.busy.Load(),
+		Queued:     pool.queued.Load(),
+		Completed:  pool.completed.Load(),
+		Rejected:   pool.rejected.Load(),
+		Canceled:   pool.canceled.Load(),
+	}
+}
@@ -0,0 +1,49 @@
+package helper
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+type clientInfoKey struct{}
+
+// DeviceIDHeader carries an identifier the client generates once per
+// installation; it is part of the session fingerprint.
+const DeviceIDHeader = "X-Device-ID"
+
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+	DeviceID  string
+}
+
+// NewClientInfo reads the caller's address from the connection itself.
+// Forwarding headers are ignored because clients can set them freely.
+func NewClientInfo(request *http.Request) ClientInfo {
+	ipAddress, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		ipAddress = request.RemoteAddr
+	}
+
+	return ClientInfo{
+		IPAddress: ipAddress,
+		UserAgent: request.UserAgent(),
+		DeviceID:  request.Header.Get(DeviceIDHeader),
+	}
+}
+
+// Fingerprint identifies the client a session was created for. It is a
+// hash so the stored value does not reveal the device ID.
+func (clientInfo ClientInfo) Fingerprint() string {
+	return HashToken(clientInfo.UserAgent + "\n" + clientInfo.DeviceID)
+}
+
+func WithClientInfo(ctx context.Context, clientInfo ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, clientInfo)
+}
+
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	clientInfo, _ := ctx.Value(clientInfoKey{}).(ClientInfo)
+	return clientInfo
+}
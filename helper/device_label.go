@@ -0,0 +1,46 @@
+package helper
+
+import "strings"
+
+// DeviceLabel turns a User-Agent header into a short label such as
+// "Chrome on Windows". Order matters: Edge and Opera also claim to be
+// Chrome, and Chrome claims to be Safari.
+func DeviceLabel(userAgent string) string {
+	browser := firstMatch(userAgent, [][2]string{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+	})
+	platform := firstMatch(userAgent, [][2]string{
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	})
+
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	default:
+		return "Unknown device"
+	}
+}
+
+func firstMatch(userAgent string, patterns [][2]string) string {
+	for _, pattern := range patterns {
+		if strings.Contains(userAgent, pattern[0]) {
+			return pattern[1]
+		}
+	}
+	return ""
+}
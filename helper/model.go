@@ -1,8 +1,12 @@
 package helper
 
 import (
+	"encoding/json"
+	"golang_jwt/audit"
+	"golang_jwt/hasher"
 	"golang_jwt/model/web"
 	"golang_jwt/model/domain"
+	"strings"
 )
 
 func ToUserResponse(user domain.User) web.UserResponse {
@@ -10,6 +14,7 @@ func ToUserResponse(user domain.User) web.UserResponse {
 		Id: user.ID,
 		Username: user.Username,
 		Email: user.Email,
+		Role: user.Role,
 	}
 }
 
@@ -22,19 +27,182 @@ func ToUserResponses(users []domain.User) []web.UserResponse {
 }
 
 func ToUserLoginResponse(accessToken string, accessClaims *web.UserClaims, refreshToken string, session domain.Session, user domain.User) web.UserLoginResponse {
+	userResponse := ToUserResponse(user)
 	return web.UserLoginResponse{
 		Session_Id: session.ID,
 		AccessToken: accessToken,
+		TokenType: accessClaims.Scheme(),
 		RefreshToken: refreshToken,
-		AccessTokenExpiresAt: accessClaims.ExpiresAt.Time,
-		RefreshTokenExpiresAt: session.Expires_At,
-		User: ToUserResponse(user),
+		AccessTokenExpiresAt: &accessClaims.ExpiresAt.Time,
+		RefreshTokenExpiresAt: &session.Expires_At,
+		SessionExpiresAt: &session.Absolute_Expires_At,
+		User: &userResponse,
+	}
+}
+
+func ToMfaChallengeResponse(mfaToken string, mfaClaims *web.UserClaims, methods []string) web.UserLoginResponse {
+	return web.UserLoginResponse{
+		MfaRequired: true,
+		MfaToken: mfaToken,
+		MfaMethods: methods,
+		MfaTokenExpiresAt: &mfaClaims.ExpiresAt.Time,
 	}
 }
 
 func ToRenewAccessTokenResponse(accessToken string, accessClaims *web.UserClaims) web.RenewAccessTokenResponse {
 	return web.RenewAccessTokenResponse{
 		AccessToken: accessToken,
+		TokenType: accessClaims.Scheme(),
 		AccessTokenExpiresAt: accessClaims.ExpiresAt.Time,
 	}
-}
\ No newline at end of file
+}
+
+func ToRenewSessionResponse(accessToken string, accessClaims *web.UserClaims, session domain.Session) web.RenewAccessTokenResponse {
+	response := ToRenewAccessTokenResponse(accessToken, accessClaims)
+	response.RefreshTokenExpiresAt = &session.Expires_At
+	response.SessionExpiresAt = &session.Absolute_Expires_At
+	return response
+}
+func ToPasskeyResponse(credential domain.WebauthnCredential) web.PasskeyResponse {
+	transports := []string{}
+	if credential.Transports != "" {
+		transports = strings.Split(credential.Transports, ",")
+	}
+	return web.PasskeyResponse{
+		Id: credential.ID,
+		Name: credential.Name,
+		Transports: transports,
+		CreatedAt: credential.CreatedAt,
+		LastUsedAt: credential.LastUsedAt,
+	}
+}
+
+func ToPasskeyResponses(credentials []domain.WebauthnCredential) []web.PasskeyResponse {
+	passkeyResponses := []web.PasskeyResponse{}
+	for _, credential := range credentials {
+		passkeyResponses = append(passkeyResponses, ToPasskeyResponse(credential))
+	}
+	return passkeyResponses
+}
+
+func ToTrustedDeviceResponses(trustedDevices []domain.TrustedDevice) []web.TrustedDeviceResponse {
+	trustedDeviceResponses := []web.TrustedDeviceResponse{}
+	for _, trustedDevice := range trustedDevices {
+		trustedDeviceResponses = append(trustedDeviceResponses, web.TrustedDeviceResponse{
+			Id: trustedDevice.ID,
+			Label: trustedDevice.Label,
+			IPAddress: trustedDevice.IPAddress,
+			CreatedAt: trustedDevice.CreatedAt,
+			LastUsedAt: trustedDevice.LastUsedAt,
+			ExpiresAt: trustedDevice.ExpiresAt,
+		})
+	}
+	return trustedDeviceResponses
+}
+
+func ToSessionResponses(sessions []domain.Session, currentSessionId string) []web.SessionResponse {
+	sessionResponses := []web.SessionResponse{}
+	for _, session := range sessions {
+		sessionResponses = append(sessionResponses, web.SessionResponse{
+			Id: session.ID,
+			DeviceLabel: session.Device_Label,
+			IPAddress: session.Ip_Address,
+			UserAgent: session.User_Agent,
+			CreatedAt: session.Created_At,
+			LastUsedAt: session.Last_Used_At,
+			ExpiresAt: session.Expires_At,
+			Current: session.ID == currentSessionId,
+		})
+	}
+	return sessionResponses
+}
+
+func ToPasswordHashingMetricsResponse(metrics hasher.PoolMetrics) web.PasswordHashingMetricsResponse {
+	return web.PasswordHashingMetricsResponse{
+		Workers:    metrics.Workers,
+		QueueDepth: metrics.QueueDepth,
+		Busy:       metrics.Busy,
+		Queued:     metrics.Queued,
+		Completed:  metrics.Completed,
+		Rejected:   metrics.Rejected,
+		Canceled:   metrics.Canceled,
+	}
+}
+
+func ToAuditEventResponses(events []audit.Event) []web.AuditEventResponse {
+	auditEventResponses := []web.AuditEventResponse{}
+	for _, event := range events {
+		auditEventResponses = append(auditEventResponses, web.AuditEventResponse{
+			Id:         event.ID,
+			Type:       string(event.Type),
+			Outcome:    string(event.Outcome),
+			ActorId:    event.ActorID,
+			UserId:     event.UserID,
+			SessionId:  event.SessionID,
+			IPAddress:  event.IPAddress,
+			UserAgent:  event.UserAgent,
+			Metadata:   event.Metadata,
+			OccurredAt: event.OccurredAt,
+		})
+	}
+	return auditEventResponses
+}
+
+func ToAuditChainVerificationResponse(verification audit.ChainVerification) web.AuditChainVerificationResponse {
+	return web.AuditChainVerificationResponse{
+		Valid:              verification.Valid,
+		CheckedEvents:      verification.CheckedEvents,
+		CheckedCheckpoints: verification.CheckedCheckpoints,
+		LastEventId:        verification.LastEventID,
+		BrokenEventId:      verification.BrokenEventID,
+		Reason:             verification.Reason,
+	}
+}
+
+func ToWebhookEndpointResponse(endpoint domain.WebhookEndpoint) web.WebhookEndpointResponse {
+	return web.WebhookEndpointResponse{
+		Id:          endpoint.ID,
+		URL:         endpoint.URL,
+		Events:      endpoint.Events,
+		Description: endpoint.Description,
+		CreatedAt:   endpoint.CreatedAt,
+	}
+}
+
+func ToWebhookEndpointResponses(endpoints []domain.WebhookEndpoint) []web.WebhookEndpointResponse {
+	webhookEndpointResponses := []web.WebhookEndpointResponse{}
+	for _, endpoint := range endpoints {
+		webhookEndpointResponses = append(webhookEndpointResponses, ToWebhookEndpointResponse(endpoint))
+	}
+	return webhookEndpointResponses
+}
+
+// ToWebhookDeliveryResponse only reports the next attempt of deliveries
+// that are still pending.
+func ToWebhookDeliveryResponse(delivery domain.WebhookDelivery) web.WebhookDeliveryResponse {
+	webhookDeliveryResponse := web.WebhookDeliveryResponse{
+		Id:             delivery.ID,
+		WebhookId:      delivery.EndpointID,
+		EventId:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		Payload:        json.RawMessage(delivery.Payload),
+		CreatedAt:      delivery.CreatedAt,
+		UpdatedAt:      delivery.UpdatedAt,
+	}
+	if delivery.Status == domain.WebhookDeliveryPending {
+		webhookDeliveryResponse.NextAttemptAt = &delivery.NextAttemptAt
+	}
+	return webhookDeliveryResponse
+}
+
+func ToWebhookDeliveryResponses(deliveries []domain.WebhookDelivery) []web.WebhookDeliveryResponse {
+	webhookDeliveryResponses := []web.WebhookDeliveryResponse{}
+	for _, delivery := range deliveries {
+		webhookDeliveryResponses = append(webhookDeliveryResponses, ToWebhookDeliveryResponse(delivery))
+	}
+	return webhookDeliveryResponses
+}
@@ -0,0 +1,23 @@
+package helper
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+func GenerateRandomToken(length int) string {
+	bytes := make([]byte, length)
+	_, err := rand.Read(bytes)
+	ErrorConditionCheck(err)
+
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+// HashToken returns the hex SHA-256 digest used to store high-entropy
+// secrets such as reset tokens without keeping them in plain text.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
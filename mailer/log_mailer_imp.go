@@ -0,0 +1,20 @@
+package mailer
+
+import (
+	"context"
+	"log"
+)
+
+// LogMailerImpl writes messages to the application log instead of sending
+// them. It is used when no SMTP host is configured.
+type LogMailerImpl struct {
+}
+
+func NewLogMailer() Mailer {
+	return &LogMailerImpl{}
+}
+
+func (mailer *LogMailerImpl) Send(ctx context.Context, message Message) error {
+	log.Printf("Mail to %s: %s\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
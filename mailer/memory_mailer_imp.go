@@ -0,0 +1,52 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryMailerImpl keeps sent messages in memory so tests and local tools
+// can read the links and codes that would have been emailed.
+type MemoryMailerImpl struct {
+	mutex    sync.Mutex
+	messages []Message
+}
+
+func NewMemoryMailer() *MemoryMailerImpl {
+	return &MemoryMailerImpl{}
+}
+
+func (mailer *MemoryMailerImpl) Send(ctx context.Context, message Message) error {
+	mailer.mutex.Lock()
+	defer mailer.mutex.Unlock()
+
+	mailer.messages = append(mailer.messages, message)
+	return nil
+}
+
+func (mailer *MemoryMailerImpl) Messages() []Message {
+	mailer.mutex.Lock()
+	defer mailer.mutex.Unlock()
+
+	return append([]Message(nil), mailer.messages...)
+}
+
+// LastTo returns the most recent message sent to the given address.
+func (mailer *MemoryMailerImpl) LastTo(to string) (Message, bool) {
+	mailer.mutex.Lock()
+	defer mailer.mutex.Unlock()
+
+	for i := len(mailer.messages) - 1; i >= 0; i-- {
+		if mailer.messages[i].To == to {
+			return mailer.messages[i], true
+		}
+	}
+	return Message{}, false
+}
+
+func (mailer *MemoryMailerImpl) Reset() {
+	mailer.mutex.Lock()
+	defer mailer.mutex.Unlock()
+
+	mailer.messages = nil
+}
@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+type SmtpMailerImpl struct {
+	Config Config
+}
+
+func NewSmtpMailer(config Config) Mailer {
+	return &SmtpMailerImpl{
+		Config: config,
+	}
+}
+
+func (mailer *SmtpMailerImpl) Send(ctx context.Context, message Message) error {
+	address := fmt.Sprintf("%s:%d", mailer.Config.Host, mailer.Config.Port)
+
+	var auth smtp.Auth
+	if mailer.Config.Username != "" {
+		auth = smtp.PlainAuth("", mailer.Config.Username, mailer.Config.Password, mailer.Config.Host)
+	}
+
+	body := strings.Join([]string{
+		"From: " + mailer.Config.From,
+		"To: " + message.To,
+		"Subject: " + message.Subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		message.Body,
+	}, "\r\n")
+
+	return smtp.SendMail(address, auth, mailer.Config.From, []string{message.To}, []byte(body))
+}
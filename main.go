@@ -2,42 +2,121 @@ package main
 
 import (
 	"golang_jwt/app"
+	"golang_jwt/asn"
+	"golang_jwt/audit"
 	"golang_jwt/controller"
+	"golang_jwt/dpop"
+	"golang_jwt/hasher"
 	"golang_jwt/helper"
+	"golang_jwt/mailer"
+	"golang_jwt/middleware"
+	"golang_jwt/policy"
+	"golang_jwt/ratelimit"
 	"golang_jwt/repository"
 	"golang_jwt/service"
 	"golang_jwt/token"
 	"golang_jwt/scheduler"
-	"os"
+	"golang_jwt/webhook"
 	"github.com/go-playground/validator/v10"
 	_ "github.com/jackc/pgx/v5/stdlib"
+	"database/sql"
 	"net/http"
-	"github.com/joho/godotenv"
 )
 
 func main() {
 
-    err := godotenv.Load()
-	helper.ErrorConditionCheck(err)
-
-	SecretKey := os.Getenv("SECRET_KEY")
+	config := app.NewConfig()
 
 	db := app.NewDB()
 	validate := validator.New()
 	userRepository := repository.NewUserRepository()
-	userToken := token.NewUserToken(SecretKey)
-	userService := service.NewUserService(userRepository, db, validate, userToken)
-	userController := controller.NewUserController(userService)
+	sessionStore := newSessionStore(config.SessionStore, db)
+	userToken := token.NewUserToken(config.SecretKey)
+	passwordHashPool := hasher.NewWorkerPool(config.PasswordHasher.Pool)
+	passwordHasher := hasher.NewPooledPasswordHasher(hasher.NewPasswordHasher(config.PasswordHasher), passwordHashPool)
+	passwordPolicy := policy.NewPasswordPolicy(config.PasswordPolicy, newBreachedPasswordChecker(config.PasswordPolicy))
+	appMailer := newMailer(config.Mailer)
+	passwordResetRepository := repository.NewPasswordResetRepository()
+	loginAttemptRepository := repository.NewLoginAttemptRepository()
+	accountUnlockRepository := repository.NewAccountUnlockRepository()
+	webauthnRepository := repository.NewWebauthnRepository()
+	recoveryCodeRepository := repository.NewRecoveryCodeRepository()
+	passwordlessTokenRepository := repository.NewPasswordlessTokenRepository()
+	trustedDeviceRepository := repository.NewTrustedDeviceRepository()
+	auditStore := audit.NewPostgresStore(db, []byte(config.SecretKey))
+	webhookRepository := repository.NewWebhookRepository()
+	webhookService := service.NewWebhookService(webhookRepository, db, validate, webhook.NewHttpSender(config.Webhook.Sender), config.Webhook)
+	dpopVerifier := dpop.NewVerifier(config.Dpop, dpop.NewMemoryReplayCache())
+	rateLimitStore := newRateLimitStore(config.RateLimitStore, db)
+	rateLimiter := ratelimit.NewLimiter(rateLimitStore, config.RateLimit.Algorithm)
+	loginLockoutService := service.NewLoginLockoutService(loginAttemptRepository, accountUnlockRepository, userRepository, db, validate, appMailer, auditStore, config.Lockout)
+	sessionIssuer := service.NewSessionIssuer(sessionStore, userToken, auditStore, webhookService, newAsnResolver(config.AsnDatabase), config.Session)
+	trustedDeviceService := service.NewTrustedDeviceService(trustedDeviceRepository, db, config.TrustedDevice)
+	mfaService := service.NewMfaService(userRepository, recoveryCodeRepository, db, validate, userToken, loginLockoutService, sessionIssuer, trustedDeviceService, auditStore, webhookService, config.Mfa)
+	userService := service.NewUserService(userRepository, sessionStore, db, validate, userToken, passwordHasher, passwordPolicy, loginLockoutService, appMailer, sessionIssuer, mfaService, trustedDeviceService, auditStore, webhookService)
+	passkeyService := service.NewPasskeyService(userRepository, webauthnRepository, db, validate, loginLockoutService, sessionIssuer, auditStore, webhookService, config.Passkey)
+	passwordlessService := service.NewPasswordlessService(userRepository, passwordlessTokenRepository, db, validate, loginLockoutService, sessionIssuer, trustedDeviceService, auditStore, webhookService, appMailer, config.Passwordless)
+	passwordService := service.NewPasswordService(userRepository, sessionStore, passwordResetRepository, db, validate, passwordHasher, passwordPolicy, trustedDeviceService, appMailer, webhookService, config.PasswordReset)
+	userController := controller.NewUserController(userService, config.Cookie)
+	passwordController := controller.NewPasswordController(passwordService)
+	lockoutController := controller.NewLockoutController(loginLockoutService)
+	mfaController := controller.NewMfaController(mfaService, config.Cookie)
+	passkeyController := controller.NewPasskeyController(passkeyService, config.Cookie)
+	passwordlessController := controller.NewPasswordlessController(passwordlessService, config.Cookie)
+	trustedDeviceController := controller.NewTrustedDeviceController(trustedDeviceService)
+	metricsController := controller.NewMetricsController(passwordHashPool)
+	auditService := service.NewAuditService(auditStore, validate)
+	auditController := controller.NewAuditController(auditService)
+	webhookController := controller.NewWebhookController(webhookService)
 
-	cleanupScheduler := scheduler.NewCleanupScheduler(userRepository, db)
+	cleanupScheduler := scheduler.NewCleanupScheduler(sessionStore, rateLimitStore)
 	cleanupScheduler.Start()
+	auditCheckpointScheduler := scheduler.NewAuditCheckpointScheduler(auditStore, config.Audit.CheckpointInterval)
+	auditCheckpointScheduler.Start()
+	webhookDispatchScheduler := scheduler.NewWebhookDispatchScheduler(webhookService, config.Webhook.DispatchInterval)
+	webhookDispatchScheduler.Start()
 
-	router := app.NewRouter(userController, userToken)
+	router := app.NewRouter(userController, passwordController, lockoutController, mfaController, passkeyController, passwordlessController, trustedDeviceController, metricsController, auditController, webhookController, userToken, userService, config.StepUp, config.Cookie, dpopVerifier, rateLimiter, config.RateLimit)
 	server := http.Server{
 		Addr: "localhost:3000",
-		Handler: router,
+		Handler: middleware.ClientInfoMiddleware(middleware.CsrfMiddleware(config.Cookie, router)),
 	}
 	
-	err = server.ListenAndServe()
+	err := server.ListenAndServe()
 	helper.ErrorConditionCheck(err)
-}
\ No newline at end of file
+}
+
+func newBreachedPasswordChecker(config policy.Config) policy.BreachedPasswordChecker {
+	if config.BreachedPasswordsPath == "" {
+		return nil
+	}
+	return policy.NewFileBreachedPasswordChecker(config.BreachedPasswordsPath)
+}
+
+func newSessionStore(store string, db *sql.DB) repository.SessionStore {
+	if store == "memory" {
+		return repository.NewMemorySessionStore()
+	}
+	return repository.NewPostgresSessionStore(db)
+}
+
+func newRateLimitStore(store string, db *sql.DB) ratelimit.Store {
+	if store == "postgres" {
+		return ratelimit.NewPostgresStore(db)
+	}
+	return ratelimit.NewMemoryStore()
+}
+
+func newAsnResolver(path string) asn.Resolver {
+	if path == "" {
+		return nil
+	}
+	return asn.NewFileResolver(path)
+}
+
+func newMailer(config mailer.Config) mailer.Mailer {
+	if config.Host == "" {
+		return mailer.NewLogMailer()
+	}
+	return mailer.NewSmtpMailer(config)
+}
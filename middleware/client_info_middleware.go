@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"net/http"
+
+	"golang_jwt/helper"
+)
+
+// ClientInfoMiddleware makes the caller's IP address and user agent available
+// to the service layer through the request context.
+func ClientInfoMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := helper.WithClientInfo(r.Context(), helper.NewClientInfo(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
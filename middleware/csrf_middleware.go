@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"golang_jwt/helper"
+	"golang_jwt/model/web"
+)
+
+const (
+	AccessTokenCookie  = "access_token"
+	RefreshTokenCookie = "refresh_token"
+	CsrfTokenCookie    = "csrf_token"
+	CsrfTokenHeader    = "X-CSRF-Token"
+)
+
+// CookieConfig enables the cookie session mode for browser clients. The
+// refresh token, and the access token when AccessToken is set, are kept in
+// HttpOnly cookies instead of the response body.
+type CookieConfig struct {
+	Enabled     bool
+	AccessToken bool
+	Secure      bool
+	SameSite    http.SameSite
+	Domain      string
+}
+
+// CsrfMiddleware applies the double-submit check: a state-changing request
+// that carries session cookies and no Authorization header must repeat the
+// csrf_token cookie in the X-CSRF-Token header. A cross-site page can make
+// the browser send the cookies but cannot read them to set the header.
+func CsrfMiddleware(config CookieConfig, next http.Handler) http.Handler {
+	if !config.Enabled {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isSafeMethod(r.Method) || r.Header.Get("Authorization") != "" || !hasSessionCookie(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		csrfCookie, err := r.Cookie(CsrfTokenCookie)
+		csrfHeader := r.Header.Get(CsrfTokenHeader)
+		if err != nil || csrfCookie.Value == "" || subtle.ConstantTimeCompare([]byte(csrfCookie.Value), []byte(csrfHeader)) != 1 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			helper.WriteToResponseBody(w, web.WebResponse{
+				Code:   http.StatusForbidden,
+				Status: "FORBIDDEN",
+				Data:   "missing or invalid CSRF token",
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func hasSessionCookie(r *http.Request) bool {
+	for _, name := range []string{AccessTokenCookie, RefreshTokenCookie} {
+		if _, err := r.Cookie(name); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+
+	"golang_jwt/dpop"
+	"golang_jwt/helper"
+	"golang_jwt/model/web"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// AcceptDpopProof is placed on the endpoints that issue tokens. A request
+// with a valid DPoP header gets tokens bound to the proof's key; requests
+// without one keep receiving Bearer tokens.
+func AcceptDpopProof(verifier *dpop.Verifier) func(httprouter.Handle) httprouter.Handle {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+			proofs := r.Header.Values(dpop.HeaderName)
+			if len(proofs) == 0 {
+				next(w, r, ps)
+				return
+			}
+
+			thumbprint := ""
+			var err error = dpop.ErrInvalidProof
+			if len(proofs) == 1 {
+				thumbprint, err = verifier.Verify(proofs[0], r.Method, verifier.RequestURL(r), "")
+			}
+			if err != nil {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				helper.WriteToResponseBody(w, web.WebResponse{
+					Code:   http.StatusBadRequest,
+					Status: "BAD REQUEST",
+					Data:   ErrInvalidDpopProof.Error(),
+				})
+				return
+			}
+
+			next(w, r.WithContext(dpop.WithThumbprint(r.Context(), thumbprint)), ps)
+		}
+	}
+}
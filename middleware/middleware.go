@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"strings"
 
+	"golang_jwt/audit"
+	"golang_jwt/dpop"
 	"golang_jwt/helper"
 	"golang_jwt/model/web"
 	"golang_jwt/token"
@@ -22,15 +24,29 @@ const (
 var (
 	ErrMissingAuthHeader = errors.New("missing or invalid Authorization header")
 	ErrInvalidToken      = errors.New("invalid or expired token")
+	ErrRevokedToken      = errors.New("token has been revoked")
+	ErrInvalidDpopProof  = errors.New("missing or invalid DPoP proof")
 )
 
+// TokenVersionChecker reports the user's current token version. Tokens
+// carrying an older version were issued before a logout from all devices.
+type TokenVersionChecker interface {
+	CurrentTokenVersion(ctx context.Context, userId int) (int, error)
+}
+
 type AuthMiddleware struct {
-	userToken token.UserToken
+	userToken           token.UserToken
+	tokenVersionChecker TokenVersionChecker
+	cookieConfig        CookieConfig
+	dpopVerifier        *dpop.Verifier
 }
 
-func NewAuthMiddleware(userToken token.UserToken) *AuthMiddleware {
+func NewAuthMiddleware(userToken token.UserToken, tokenVersionChecker TokenVersionChecker, cookieConfig CookieConfig, dpopVerifier *dpop.Verifier) *AuthMiddleware {
 	return &AuthMiddleware{
-		userToken: userToken,
+		userToken:           userToken,
+		tokenVersionChecker: tokenVersionChecker,
+		cookieConfig:        cookieConfig,
+		dpopVerifier:        dpopVerifier,
 	}
 }
 
@@ -44,15 +60,19 @@ func (m *AuthMiddleware) Handle() func(httprouter.Handle) httprouter.Handle {
 			}
 
 			ctx := context.WithValue(r.Context(), UserClaimsKey, claims)
+			ctx = audit.WithActor(ctx, audit.Actor{UserID: claims.ID, SessionID: claims.SessionID})
 			next(w, r.WithContext(ctx), ps)
 		}
 	}
 }
 
 func (m *AuthMiddleware) authenticate(r *http.Request) (*web.UserClaims, error) {
-	token, err := m.extractBearerToken(r)
+	token, scheme, err := m.extractAuthorizationToken(r)
 	if err != nil {
-		return nil, err
+		token, err = m.extractCookieToken(r, err)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	claims, err := m.validateTokenSafely(token)
@@ -60,26 +80,92 @@ func (m *AuthMiddleware) authenticate(r *http.Request) (*web.UserClaims, error)
 		return nil, err
 	}
 
+	if err := m.checkDpopBinding(r, token, scheme, claims); err != nil {
+		return nil, err
+	}
+
+	if err := m.checkTokenVersion(r.Context(), claims); err != nil {
+		return nil, err
+	}
+
 	return claims, nil
 }
 
-func (m *AuthMiddleware) extractBearerToken(r *http.Request) (string, error) {
+func (m *AuthMiddleware) checkTokenVersion(ctx context.Context, claims *web.UserClaims) error {
+	if m.tokenVersionChecker == nil {
+		return nil
+	}
+
+	currentVersion, err := m.tokenVersionChecker.CurrentTokenVersion(ctx, claims.ID)
+	if err != nil || claims.TokenVersion < currentVersion {
+		return ErrRevokedToken
+	}
+
+	return nil
+}
+
+// checkDpopBinding enforces RFC 9449 for tokens bound with cnf.jkt: they
+// need a proof signed by the bound key for this request and this token, and
+// must not be downgraded to the Bearer scheme. Unbound tokens are refused
+// under the DPoP scheme.
+func (m *AuthMiddleware) checkDpopBinding(r *http.Request, token string, scheme string, claims *web.UserClaims) error {
+	if claims.Confirmation == nil {
+		if scheme == web.TokenSchemeDpop {
+			return ErrInvalidToken
+		}
+		return nil
+	}
+
+	proofs := r.Header.Values(dpop.HeaderName)
+	if scheme == web.TokenSchemeBearer || m.dpopVerifier == nil || len(proofs) != 1 {
+		return ErrInvalidDpopProof
+	}
+
+	thumbprint, err := m.dpopVerifier.Verify(proofs[0], r.Method, m.dpopVerifier.RequestURL(r), token)
+	if err != nil || thumbprint != claims.Confirmation.JKT {
+		return ErrInvalidDpopProof
+	}
+
+	return nil
+}
+
+// extractAuthorizationToken accepts the Bearer and DPoP schemes and returns
+// the scheme along with the token.
+func (m *AuthMiddleware) extractAuthorizationToken(r *http.Request) (string, string, error) {
 	authHeader := r.Header.Get("Authorization")
 	
 	if authHeader == "" {
-		return "", ErrMissingAuthHeader
+		return "", "", ErrMissingAuthHeader
 	}
 
-	if !strings.HasPrefix(authHeader, "Bearer ") {
-		return "", ErrMissingAuthHeader
+	for _, scheme := range []string{web.TokenSchemeBearer, web.TokenSchemeDpop} {
+		if !strings.HasPrefix(authHeader, scheme+" ") {
+			continue
+		}
+
+		token := strings.TrimSpace(strings.TrimPrefix(authHeader, scheme))
+		if token == "" {
+			return "", "", ErrMissingAuthHeader
+		}
+		return token, scheme, nil
+	}
+
+	return "", "", ErrMissingAuthHeader
+}
+
+// extractCookieToken is the fallback for browser clients in cookie mode. The
+// Authorization header still takes precedence when it is present.
+func (m *AuthMiddleware) extractCookieToken(r *http.Request, headerErr error) (string, error) {
+	if !m.cookieConfig.Enabled || !m.cookieConfig.AccessToken || r.Header.Get("Authorization") != "" {
+		return "", headerErr
 	}
 
-	token := strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer"))
-	if token == "" {
-		return "", ErrMissingAuthHeader
+	cookie, err := r.Cookie(AccessTokenCookie)
+	if err != nil || cookie.Value == "" {
+		return "", headerErr
 	}
 
-	return token, nil
+	return cookie.Value, nil
 }
 
 func (m *AuthMiddleware) validateTokenSafely(tokenString string) (*web.UserClaims, error) {
@@ -94,7 +180,7 @@ func (m *AuthMiddleware) validateTokenSafely(tokenString string) (*web.UserClaim
 	}()
 
 	claims, err = m.userToken.ValidateToken(tokenString)
-	if err != nil {
+	if err != nil || claims.TokenType != web.TokenTypeAccess {
 		return nil, ErrInvalidToken
 	}
 
@@ -102,6 +188,9 @@ func (m *AuthMiddleware) validateTokenSafely(tokenString string) (*web.UserClaim
 }
 
 func (m *AuthMiddleware) handleAuthError(w http.ResponseWriter, err error) {
+	if err == ErrInvalidDpopProof {
+		w.Header().Set("WWW-Authenticate", `DPoP error="invalid_dpop_proof", algs="`+strings.Join(dpop.Algorithms, " ")+`"`)
+	}
 	w.WriteHeader(http.StatusUnauthorized)
 	
 	response := web.WebResponse{
@@ -114,7 +203,7 @@ func (m *AuthMiddleware) handleAuthError(w http.ResponseWriter, err error) {
 }
 
 // Legacy function for backward compatibility - RENAME FUNCTION
-func CreateAuthMiddleware(userToken token.UserToken) func(httprouter.Handle) httprouter.Handle {
-	middleware := NewAuthMiddleware(userToken)
+func CreateAuthMiddleware(userToken token.UserToken, tokenVersionChecker TokenVersionChecker, cookieConfig CookieConfig, dpopVerifier *dpop.Verifier) func(httprouter.Handle) httprouter.Handle {
+	middleware := NewAuthMiddleware(userToken, tokenVersionChecker, cookieConfig, dpopVerifier)
 	return middleware.Handle()
 }
\ No newline at end of file
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang_jwt/exception"
+	"golang_jwt/helper"
+	"golang_jwt/ratelimit"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// ClientIDHeader identifies the calling application for rules keyed by
+// client.
+const ClientIDHeader = "X-Client-ID"
+
+// maxAccountBodyBytes bounds how much of the body is read to find the
+// account, as this happens before any handler. Credential requests are far
+// smaller; a larger body is cut off and fails to decode in the handler.
+const maxAccountBodyBytes = 64 << 10
+
+// RateLimit throttles a route. Requests are counted per rule under scope,
+// so routes sharing a rule still have separate quotas, and rules whose key
+// is missing from the request are skipped. The RateLimit-* headers describe
+// the rule closest to its limit. When the store fails the request is let
+// through, so an outage of the counters does not lock everyone out.
+func RateLimit(limiter *ratelimit.Limiter, scope string, rules []ratelimit.Rule) func(httprouter.Handle) httprouter.Handle {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+			var reported *ratelimit.Result
+			var reportedRule ratelimit.Rule
+			for _, rule := range rules {
+				value := rateLimitKey(w, r, rule.Key)
+				if value == "" {
+					continue
+				}
+
+				result, err := limiter.Allow(r.Context(), rule, scope+":"+rule.Key+":"+value)
+				if err != nil {
+					log.Printf("Error checking rate limit for %s: %v", scope, err)
+					continue
+				}
+
+				if reported == nil || !result.Allowed || result.Remaining < reported.Remaining {
+					reported, reportedRule = &result, rule
+				}
+				if !result.Allowed {
+					break
+				}
+			}
+
+			if reported == nil {
+				next(w, r, ps)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(reported.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(reported.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(reported.Reset)))
+			w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", reportedRule.Limit, ceilSeconds(reportedRule.Window)))
+			if !reported.Allowed {
+				panic(exception.NewTooManyRequestsError("too many requests, please try again later", reported.RetryAfter))
+			}
+
+			next(w, r, ps)
+		}
+	}
+}
+
+// rateLimitKey returns the value a rule counts by. Account names are taken
+// from the JSON body and hashed, so the counters do not store them.
+func rateLimitKey(w http.ResponseWriter, r *http.Request, key string) string {
+	switch key {
+	case ratelimit.KeyIP:
+		return helper.ClientInfoFromContext(r.Context()).IPAddress
+	case ratelimit.KeyClient:
+		return r.Header.Get(ClientIDHeader)
+	case ratelimit.KeyAccount:
+		account := strings.ToLower(strings.TrimSpace(accountFromBody(w, r)))
+		if account == "" {
+			return ""
+		}
+		return helper.HashToken(account)
+	}
+	return ""
+}
+
+// accountFromBody reads the identifier, email or username field and puts
+// the body back for the handler.
+func accountFromBody(w http.ResponseWriter, r *http.Request) string {
+	if r.Body == nil {
+		return ""
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAccountBodyBytes))
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	fields := struct {
+		Identifier string `json:"identifier"`
+		Email      string `json:"email"`
+		Username   string `json:"username"`
+	}{}
+	if json.Unmarshal(body, &fields) != nil {
+		return ""
+	}
+
+	switch {
+	case fields.Identifier != "":
+		return fields.Identifier
+	case fields.Email != "":
+		return fields.Email
+	}
+	return fields.Username
+}
+
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
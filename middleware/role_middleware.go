@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+
+	"golang_jwt/helper"
+	"golang_jwt/model/web"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// RequireRole must be placed behind the auth middleware, which provides the claims.
+func RequireRole(roles ...string) func(httprouter.Handle) httprouter.Handle {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+			claims, ok := r.Context().Value(UserClaimsKey).(*web.UserClaims)
+			if ok {
+				for _, role := range roles {
+					if claims.Role == role {
+						next(w, r, ps)
+						return
+					}
+				}
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			helper.WriteToResponseBody(w, web.WebResponse{
+				Code:   http.StatusForbidden,
+				Status: "FORBIDDEN",
+				Data:   "insufficient permissions",
+			})
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+const (
+	LoginAttemptKeyAccount = "account"
+	LoginAttemptKeyIP      = "ip"
+)
+
+type LoginAttempt struct {
+	KeyType       string
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
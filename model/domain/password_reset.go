@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+type PasswordReset struct {
+	ID        int
+	UserID    int
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+const (
+	PasswordlessMethodLink = "link"
+	PasswordlessMethodCode = "code"
+)
+
+// PasswordlessToken is also stored for unknown addresses (UserID nil) so
+// rate limiting behaves the same whether or not an account exists.
+type PasswordlessToken struct {
+	ID        int
+	Email     string
+	UserID    *int
+	Method    string
+	TokenHash string
+	Attempts  int
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+type RecoveryCode struct {
+	ID        int
+	UserID    int
+	CodeHash  string
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
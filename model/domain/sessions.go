@@ -8,5 +8,14 @@ type Session struct {
 	Refresh_Token string
 	Is_Revoked bool
 	Created_At time.Time
+	// Expires_At slides forward on every renewal (idle timeout) but never
+	// past Absolute_Expires_At.
 	Expires_At time.Time
+	Absolute_Expires_At time.Time
+	Ip_Address string
+	User_Agent string
+	Device_Label string
+	// Fingerprint is recorded at login and compared on every renewal.
+	Fingerprint string
+	Last_Used_At time.Time
 }
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+type TrustedDevice struct {
+	ID         string
+	UserID     int
+	TokenHash  string
+	Label      string
+	IPAddress  string
+	UserAgent  string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+}
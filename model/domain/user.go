@@ -1,8 +1,18 @@
 package domain
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {  
 	ID        int    
 	Username  string 
 	Email     string 
 	Password  string 
-}
\ No newline at end of file
+	Role      string
+	TotpSecret   string
+	TotpEnabled  bool
+	TotpLastStep int64
+	TokenVersion int
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+const (
+	WebauthnCeremonyRegistration   = "registration"
+	WebauthnCeremonyAuthentication = "authentication"
+)
+
+type WebauthnChallenge struct {
+	ID        string
+	UserID    *int
+	Challenge []byte
+	Ceremony  string
+	ExpiresAt time.Time
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+type WebauthnCredential struct {
+	ID         string
+	UserID     int
+	Name       string
+	PublicKey  []byte
+	SignCount  int64
+	AAGUID     string
+	Transports string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
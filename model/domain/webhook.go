@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookEndpoint is a registered receiver. Secret is encrypted at rest;
+// Events holds the subscribed event types.
+type WebhookEndpoint struct {
+	ID          int64
+	URL         string
+	Secret      string
+	Events      []string
+	Description string
+	CreatedAt   time.Time
+}
+
+// WebhookOutboxEvent is written in the transaction of the change it
+// describes and turned into deliveries by the dispatcher.
+type WebhookOutboxEvent struct {
+	ID        int64
+	EventID   string
+	EventType string
+	Payload   string
+	CreatedAt time.Time
+}
+
+// WebhookDelivery tracks one event for one endpoint. Dead deliveries ran
+// out of attempts and are kept for inspection and redelivery.
+type WebhookDelivery struct {
+	ID             int64
+	EndpointID     int64
+	EventID        string
+	EventType      string
+	Payload        string
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type WebhookDeliveryFilter struct {
+	EndpointID int64
+	Status     string
+	EventType  string
+	BeforeID   int64
+	Limit      int
+}
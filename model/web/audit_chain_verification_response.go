@@ -0,0 +1,10 @@
+package web
+
+type AuditChainVerificationResponse struct {
+	Valid              bool   `json:"valid"`
+	CheckedEvents      int    `json:"checked_events"`
+	CheckedCheckpoints int    `json:"checked_checkpoints"`
+	LastEventId        int64  `json:"last_event_id"`
+	BrokenEventId      int64  `json:"broken_event_id,omitempty"`
+	Reason             string `json:"reason,omitempty"`
+}
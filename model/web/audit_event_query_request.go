@@ -0,0 +1,16 @@
+package web
+
+// AuditEventQueryRequest holds the query string of the audit log endpoint.
+// From and To are RFC 3339 timestamps; BeforeId pages through older events.
+type AuditEventQueryRequest struct {
+	Type      string `validate:"omitempty,max=100" json:"type"`
+	Outcome   string `validate:"omitempty,oneof=success failure" json:"outcome"`
+	ActorId   string `validate:"omitempty,number" json:"actor_id"`
+	UserId    string `validate:"omitempty,number" json:"user_id"`
+	SessionId string `validate:"omitempty,max=255" json:"session_id"`
+	IPAddress string `validate:"omitempty,ip" json:"ip_address"`
+	From      string `validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00" json:"from"`
+	To        string `validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00" json:"to"`
+	BeforeId  string `validate:"omitempty,number" json:"before_id"`
+	Limit     string `validate:"omitempty,number" json:"limit"`
+}
@@ -0,0 +1,16 @@
+package web
+
+import "time"
+
+type AuditEventResponse struct {
+	Id         int64             `json:"id"`
+	Type       string            `json:"type"`
+	Outcome    string            `json:"outcome"`
+	ActorId    int               `json:"actor_id,omitempty"`
+	UserId     int               `json:"user_id,omitempty"`
+	SessionId  string            `json:"session_id,omitempty"`
+	IPAddress  string            `json:"ip_address"`
+	UserAgent  string            `json:"user_agent"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	OccurredAt time.Time         `json:"occurred_at"`
+}
@@ -0,0 +1,6 @@
+package web
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `validate:"required,min=1,max=100" json:"current_password"`
+	NewPassword     string `validate:"required,min=1,max=100" json:"new_password"`
+}
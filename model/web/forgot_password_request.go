@@ -0,0 +1,5 @@
+package web
+
+type ForgotPasswordRequest struct {
+	Email string `validate:"required,min=1,max=100,email" json:"email"`
+}
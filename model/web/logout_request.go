@@ -0,0 +1,7 @@
+package web
+
+// RefreshToken identifies the session when the access token predates the
+// sid claim.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
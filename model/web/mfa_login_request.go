@@ -0,0 +1,9 @@
+package web
+
+// Either Code from the authenticator app or a RecoveryCode is required.
+type MfaLoginRequest struct {
+	MfaToken     string `validate:"required" json:"mfa_token"`
+	Code         string `validate:"required_without=RecoveryCode,omitempty,len=6,numeric" json:"code"`
+	RecoveryCode string `validate:"required_without=Code,omitempty,max=32" json:"recovery_code"`
+	TrustDevice  bool   `json:"trust_device"`
+}
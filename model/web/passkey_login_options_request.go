@@ -0,0 +1,7 @@
+package web
+
+// Identifier is optional; without it the browser offers any discoverable
+// passkey registered for this site.
+type PasskeyLoginOptionsRequest struct {
+	Identifier string `validate:"max=100" json:"identifier"`
+}
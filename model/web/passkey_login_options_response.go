@@ -0,0 +1,8 @@
+package web
+
+import "golang_jwt/webauthn"
+
+type PasskeyLoginOptionsResponse struct {
+	ChallengeId string                            `json:"challenge_id"`
+	PublicKey   webauthn.CredentialRequestOptions `json:"public_key"`
+}
@@ -0,0 +1,8 @@
+package web
+
+import "golang_jwt/webauthn"
+
+type PasskeyLoginRequest struct {
+	ChallengeId string                       `validate:"required,uuid" json:"challenge_id"`
+	Credential  webauthn.AssertionCredential `json:"credential"`
+}
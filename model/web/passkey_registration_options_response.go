@@ -0,0 +1,8 @@
+package web
+
+import "golang_jwt/webauthn"
+
+type PasskeyRegistrationOptionsResponse struct {
+	ChallengeId string                             `json:"challenge_id"`
+	PublicKey   webauthn.CredentialCreationOptions `json:"public_key"`
+}
@@ -0,0 +1,9 @@
+package web
+
+import "golang_jwt/webauthn"
+
+type PasskeyRegistrationRequest struct {
+	ChallengeId string                          `validate:"required,uuid" json:"challenge_id"`
+	Name        string                          `validate:"max=100" json:"name"`
+	Credential  webauthn.RegistrationCredential `json:"credential"`
+}
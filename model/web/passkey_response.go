@@ -0,0 +1,11 @@
+package web
+
+import "time"
+
+type PasskeyResponse struct {
+	Id         string     `json:"id"`
+	Name       string     `json:"name"`
+	Transports []string   `json:"transports"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
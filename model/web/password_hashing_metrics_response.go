@@ -0,0 +1,11 @@
+package web
+
+type PasswordHashingMetricsResponse struct {
+	Workers    int    `json:"workers"`
+	QueueDepth int    `json:"queue_depth"`
+	Busy       int64  `json:"busy"`
+	Queued     int64  `json:"queued"`
+	Completed  uint64 `json:"completed"`
+	Rejected   uint64 `json:"rejected"`
+	Canceled   uint64 `json:"canceled"`
+}
@@ -0,0 +1,6 @@
+package web
+
+type PasswordlessLoginRequest struct {
+	Email  string `validate:"required,min=1,max=100,email" json:"email"`
+	Method string `validate:"required,oneof=link code" json:"method"`
+}
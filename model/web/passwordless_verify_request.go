@@ -0,0 +1,10 @@
+package web
+
+// Token comes from a magic link; Email and Code are used for emailed codes.
+type PasswordlessVerifyRequest struct {
+	Token string `validate:"required_without=Code,max=100" json:"token"`
+	Email string `validate:"required_with=Code,omitempty,max=100,email" json:"email"`
+	Code  string `validate:"required_without=Token,omitempty,len=6,numeric" json:"code"`
+	// DeviceToken skips the MFA step on a trusted device, as in Login.
+	DeviceToken string `validate:"omitempty,max=100" json:"device_token"`
+}
@@ -0,0 +1,8 @@
+package web
+
+// Code or RecoveryCode is required when the account has TOTP enabled.
+type ReauthenticateRequest struct {
+	Password     string `validate:"required,min=1,max=100" json:"password"`
+	Code         string `validate:"omitempty,len=6,numeric" json:"code"`
+	RecoveryCode string `validate:"omitempty,max=32" json:"recovery_code"`
+}
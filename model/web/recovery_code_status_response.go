@@ -0,0 +1,6 @@
+package web
+
+type RecoveryCodeStatusResponse struct {
+	Total     int `json:"total"`
+	Remaining int `json:"remaining"`
+}
@@ -0,0 +1,6 @@
+package web
+
+// Codes are only returned once, right after they are generated.
+type RecoveryCodesResponse struct {
+	Codes []string `json:"codes"`
+}
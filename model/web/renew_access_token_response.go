@@ -2,7 +2,13 @@ package web
 
 import "time"
 
+// RenewAccessTokenResponse reports the session deadlines when it answers a
+// refresh: RefreshTokenExpiresAt is the idle deadline, SessionExpiresAt the
+// absolute one.
 type RenewAccessTokenResponse struct {
-	AccessToken          string    `json:"access_token"`
-	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	AccessToken           string     `json:"access_token"`
+	TokenType             string     `json:"token_type"`
+	AccessTokenExpiresAt  time.Time  `json:"access_token_expires_at"`
+	RefreshTokenExpiresAt *time.Time `json:"refresh_token_expires_at,omitempty"`
+	SessionExpiresAt      *time.Time `json:"session_expires_at,omitempty"`
 }
\ No newline at end of file
@@ -0,0 +1,6 @@
+package web
+
+type ResetPasswordRequest struct {
+	Token       string `validate:"required" json:"token"`
+	NewPassword string `validate:"required,min=1,max=100" json:"new_password"`
+}
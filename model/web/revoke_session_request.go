@@ -0,0 +1,7 @@
+package web
+
+// SessionId defaults to the caller's current session.
+type RevokeSessionRequest struct {
+	SessionId    string `validate:"omitempty,max=255" json:"session_id"`
+	RefreshToken string `json:"refresh_token"`
+}
@@ -0,0 +1,14 @@
+package web
+
+import "time"
+
+type SessionResponse struct {
+	Id          string    `json:"id"`
+	DeviceLabel string    `json:"device_label"`
+	IPAddress   string    `json:"ip_address"`
+	UserAgent   string    `json:"user_agent"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastUsedAt  time.Time `json:"last_used_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Current     bool      `json:"current"`
+}
@@ -0,0 +1,8 @@
+package web
+
+type StepUpRequiredResponse struct {
+	Error       string `json:"error"`
+	Message     string `json:"message"`
+	RequiredAcr string `json:"required_acr,omitempty"`
+	MaxAge      int    `json:"max_age,omitempty"`
+}
@@ -0,0 +1,5 @@
+package web
+
+type TotpCodeRequest struct {
+	Code string `validate:"required,len=6,numeric" json:"code"`
+}
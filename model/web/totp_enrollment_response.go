@@ -0,0 +1,6 @@
+package web
+
+type TotpEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
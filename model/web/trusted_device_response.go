@@ -0,0 +1,12 @@
+package web
+
+import "time"
+
+type TrustedDeviceResponse struct {
+	Id         string    `json:"id"`
+	Label      string    `json:"label"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
@@ -0,0 +1,5 @@
+package web
+
+type UnlockAccountRequest struct {
+	Token string `validate:"required" json:"token"`
+}
@@ -2,10 +2,79 @@ package web
 
 import "github.com/golang-jwt/jwt/v5"
 
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	TokenTypeMfa     = "mfa"
+)
+
+// Authentication method references (RFC 8176) used in the amr claim.
+// AmrEmail and AmrRecoveryCode are not registered there and mark proof of
+// mailbox access and a redeemed recovery code.
+const (
+	AmrPassword     = "pwd"
+	AmrOtp          = "otp"
+	AmrHardwareKey  = "hwk"
+	AmrEmail        = "email"
+	AmrRecoveryCode = "recovery_code"
+	AmrMultiFactor  = "mfa"
+)
+
+// Authentication context classes used in the acr claim, named after the
+// NIST SP 800-63B assurance levels.
+const (
+	AcrSingleFactor = "aal1"
+	AcrMultiFactor  = "aal2"
+)
+
+// AcrLevel orders acr values so a minimum can be enforced; unknown values
+// rank lowest.
+func AcrLevel(acr string) int {
+	switch acr {
+	case AcrSingleFactor:
+		return 1
+	case AcrMultiFactor:
+		return 2
+	default:
+		return 0
+	}
+}
+
 type UserClaims struct {
-	ID       int    `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
+	ID        int    `json:"id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	TokenType string `json:"token_type"`
+	// AuthMethods, Acr and AuthTime describe the login that created the
+	// session; refreshed tokens keep the original values.
+	AuthMethods []string         `json:"amr,omitempty"`
+	Acr         string           `json:"acr,omitempty"`
+	AuthTime    *jwt.NumericDate `json:"auth_time,omitempty"`
+	// SessionID links an access token to the session that issued it.
+	SessionID string `json:"sid,omitempty"`
+	// TokenVersion must match the user's current version; it is bumped to
+	// invalidate all outstanding tokens at once.
+	TokenVersion int `json:"ver"`
+	// Confirmation binds the token to the client's DPoP key (RFC 9449).
+	Confirmation *Confirmation `json:"cnf,omitempty"`
 	jwt.RegisteredClaims
 }
 
+type Confirmation struct {
+	JKT string `json:"jkt"`
+}
+
+const (
+	TokenSchemeBearer = "Bearer"
+	TokenSchemeDpop   = "DPoP"
+)
+
+// Scheme is the token_type reported to clients and the Authorization scheme
+// the token must be presented with.
+func (claims *UserClaims) Scheme() string {
+	if claims.Confirmation != nil {
+		return TokenSchemeDpop
+	}
+	return TokenSchemeBearer
+}
@@ -1,6 +1,11 @@
 package web
 
+// Identifier accepts either a username or an email. Email is kept for
+// clients that still send the original field. DeviceToken is the token of a
+// trusted device, which lets the login skip the second factor.
 type UserLoginRequest struct {
-	Email    string `validate:"required,min=1,max=100,email,lowercase" json:"email"`
-	Password string `validate:"required,min=1,max=100" json:"password"` 
-}
\ No newline at end of file
+	Identifier  string `validate:"required_without=Email,max=100" json:"identifier"`
+	Email       string `validate:"omitempty,max=100,email" json:"email"`
+	Password    string `validate:"required,min=1,max=100" json:"password"`
+	DeviceToken string `validate:"omitempty,max=100" json:"device_token"`
+}
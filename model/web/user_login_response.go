@@ -2,11 +2,21 @@ package web
 
 import "time"
 
+// UserLoginResponse either carries the issued tokens or, when a second
+// factor is required, only the MFA challenge.
 type UserLoginResponse struct {
-	Session_Id string `json:"session_id"`
-	AccessToken string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
-	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
-	User  UserResponse `json:"user"`
-}
\ No newline at end of file
+	Session_Id string `json:"session_id,omitempty"`
+	AccessToken string `json:"access_token,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	AccessTokenExpiresAt  *time.Time `json:"access_token_expires_at,omitempty"`
+	RefreshTokenExpiresAt *time.Time `json:"refresh_token_expires_at,omitempty"`
+	SessionExpiresAt *time.Time `json:"session_expires_at,omitempty"`
+	User  *UserResponse `json:"user,omitempty"`
+	MfaRequired bool `json:"mfa_required"`
+	MfaToken string `json:"mfa_token,omitempty"`
+	MfaMethods []string `json:"mfa_methods,omitempty"`
+	MfaTokenExpiresAt *time.Time `json:"mfa_token_expires_at,omitempty"`
+	DeviceToken string `json:"device_token,omitempty"`
+	DeviceTokenExpiresAt *time.Time `json:"device_token_expires_at,omitempty"`
+}
@@ -4,5 +4,6 @@ type UserResponse struct {
 	Id int `json:"id"`
 	Username string `json:"username"`
 	Email string `json:"email"`
+	Role string `json:"role"`
 }
 
@@ -0,0 +1,11 @@
+package web
+
+// WebhookDeliveryQueryRequest holds the query string of the delivery log
+// endpoint. BeforeId pages through older deliveries.
+type WebhookDeliveryQueryRequest struct {
+	WebhookId string `validate:"omitempty,number" json:"webhook_id"`
+	Status    string `validate:"omitempty,oneof=pending succeeded dead" json:"status"`
+	EventType string `validate:"omitempty,max=100" json:"event_type"`
+	BeforeId  string `validate:"omitempty,number" json:"before_id"`
+	Limit     string `validate:"omitempty,number" json:"limit"`
+}
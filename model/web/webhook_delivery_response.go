@@ -0,0 +1,21 @@
+package web
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type WebhookDeliveryResponse struct {
+	Id             int64           `json:"id"`
+	WebhookId      int64           `json:"webhook_id"`
+	EventId        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
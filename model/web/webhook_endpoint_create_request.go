@@ -0,0 +1,7 @@
+package web
+
+type WebhookEndpointCreateRequest struct {
+	URL         string   `validate:"required,url,max=2048" json:"url"`
+	Events      []string `validate:"required,min=1,dive,oneof=user.registered login.failed session.revoked password.changed" json:"events"`
+	Description string   `validate:"max=255" json:"description"`
+}
@@ -0,0 +1,14 @@
+package web
+
+import "time"
+
+// WebhookEndpointResponse only carries the signing secret in the response
+// to the registration; it cannot be read back later.
+type WebhookEndpointResponse struct {
+	Id          int64     `json:"id"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Description string    `json:"description"`
+	Secret      string    `json:"secret,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
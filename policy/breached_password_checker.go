@@ -0,0 +1,5 @@
+package policy
+
+type BreachedPasswordChecker interface {
+	IsBreached(password string) (bool, error)
+}
@@ -0,0 +1,102 @@
+package policy
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const hashPrefixLength = 5
+
+// FileBreachedPasswordCheckerImpl checks passwords against a local copy of a
+// breached-password corpus, without any network access. Path may be either:
+//
+//   - a directory of range files named by the first five hex characters of the
+//     SHA-1 hash (e.g. 5BAA6.txt), each line holding the remaining 35 characters
+//     and an optional ":count", as produced by the HIBP downloader; or
+//   - a single file with one full SHA-1 hash per line, optionally ":count".
+type FileBreachedPasswordCheckerImpl struct {
+	Path string
+
+	once   sync.Once
+	hashes map[string]struct{}
+	err    error
+}
+
+func NewFileBreachedPasswordChecker(path string) BreachedPasswordChecker {
+	return &FileBreachedPasswordCheckerImpl{
+		Path: path,
+	}
+}
+
+func (checker *FileBreachedPasswordCheckerImpl) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	info, err := os.Stat(checker.Path)
+	if err != nil {
+		return false, err
+	}
+
+	if info.IsDir() {
+		return checker.searchRangeFile(hash)
+	}
+
+	checker.once.Do(checker.loadHashFile)
+	if checker.err != nil {
+		return false, checker.err
+	}
+
+	_, found := checker.hashes[hash]
+	return found, nil
+}
+
+func (checker *FileBreachedPasswordCheckerImpl) searchRangeFile(hash string) (bool, error) {
+	prefix, suffix := hash[:hashPrefixLength], hash[hashPrefixLength:]
+
+	file, err := os.Open(filepath.Join(checker.Path, prefix+".txt"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if strings.EqualFold(hashOfLine(scanner.Text()), suffix) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+func (checker *FileBreachedPasswordCheckerImpl) loadHashFile() {
+	file, err := os.Open(checker.Path)
+	if err != nil {
+		checker.err = err
+		return
+	}
+	defer file.Close()
+
+	checker.hashes = make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		hash := strings.ToUpper(hashOfLine(scanner.Text()))
+		if hash != "" {
+			checker.hashes[hash] = struct{}{}
+		}
+	}
+	checker.err = scanner.Err()
+}
+
+func hashOfLine(line string) string {
+	hash, _, _ := strings.Cut(strings.TrimSpace(line), ":")
+	return hash
+}
@@ -0,0 +1,27 @@
+package policy
+
+import "golang_jwt/model/web"
+
+type PasswordPolicy interface {
+	Validate(field string, password string, identity PasswordIdentity) ([]web.FieldError, error)
+}
+
+// PasswordIdentity carries the account identifiers a password must not contain.
+type PasswordIdentity struct {
+	Username string
+	Email    string
+}
+
+// MaxBytes limits the encoded length for hashers that would truncate longer
+// passwords; 0 means no limit.
+type Config struct {
+	MinLength             int
+	MaxBytes              int
+	RequireUppercase      bool
+	RequireLowercase      bool
+	RequireDigit          bool
+	RequireSymbol         bool
+	MinEntropyBits        float64
+	BlockUserIdentifiers  bool
+	BreachedPasswordsPath string
+}
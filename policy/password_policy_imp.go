@@ -0,0 +1,157 @@
+package policy
+
+import (
+	"fmt"
+	"golang_jwt/model/web"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type PasswordPolicyImpl struct {
+	Config                  Config
+	BreachedPasswordChecker BreachedPasswordChecker
+}
+
+func NewPasswordPolicy(config Config, breachedPasswordChecker BreachedPasswordChecker) PasswordPolicy {
+	return &PasswordPolicyImpl{
+		Config:                  config,
+		BreachedPasswordChecker: breachedPasswordChecker,
+	}
+}
+
+func (policy *PasswordPolicyImpl) Validate(field string, password string, identity PasswordIdentity) ([]web.FieldError, error) {
+	var violations []web.FieldError
+	violation := func(rule string, message string) {
+		violations = append(violations, web.FieldError{
+			Field:   field,
+			Rule:    rule,
+			Message: message,
+		})
+	}
+
+	if utf8.RuneCountInString(password) < policy.Config.MinLength {
+		violation("min_length", fmt.Sprintf("password must be at least %d characters long", policy.Config.MinLength))
+	}
+	if policy.Config.MaxBytes > 0 && len(password) > policy.Config.MaxBytes {
+		violation("max_length", fmt.Sprintf("password must be at most %d bytes long", policy.Config.MaxBytes))
+	}
+
+	classes := characterClassesOf(password)
+	if policy.Config.RequireUppercase && !classes.upper {
+		violation("uppercase", "password must contain an uppercase letter")
+	}
+	if policy.Config.RequireLowercase && !classes.lower {
+		violation("lowercase", "password must contain a lowercase letter")
+	}
+	if policy.Config.RequireDigit && !classes.digit {
+		violation("digit", "password must contain a digit")
+	}
+	if policy.Config.RequireSymbol && !classes.symbol {
+		violation("symbol", "password must contain a symbol")
+	}
+
+	if policy.Config.MinEntropyBits > 0 && EntropyBits(password) < policy.Config.MinEntropyBits {
+		violation("entropy", "password is too easy to guess")
+	}
+
+	if policy.Config.BlockUserIdentifiers && containsIdentity(password, identity) {
+		violation("identity", "password must not contain the username or email")
+	}
+
+	if policy.BreachedPasswordChecker != nil {
+		breached, err := policy.BreachedPasswordChecker.IsBreached(password)
+		if err != nil {
+			return nil, err
+		}
+		if breached {
+			violation("breached", "password has appeared in a data breach")
+		}
+	}
+
+	return violations, nil
+}
+
+type characterClasses struct {
+	upper  bool
+	lower  bool
+	digit  bool
+	symbol bool
+	other  bool
+}
+
+func characterClassesOf(password string) characterClasses {
+	classes := characterClasses{}
+	for _, r := range password {
+		switch {
+		case r <= unicode.MaxASCII && unicode.IsUpper(r):
+			classes.upper = true
+		case r <= unicode.MaxASCII && unicode.IsLower(r):
+			classes.lower = true
+		case unicode.IsDigit(r):
+			classes.digit = true
+		case r <= unicode.MaxASCII && (unicode.IsPunct(r) || unicode.IsSymbol(r) || r == ' '):
+			classes.symbol = true
+		default:
+			classes.other = true
+		}
+	}
+	return classes
+}
+
+// EntropyBits estimates the brute-force search space of a password from the
+// character pool it draws on. Characters repeating the previous one add nothing.
+func EntropyBits(password string) float64 {
+	classes := characterClassesOf(password)
+
+	pool := 0
+	if classes.upper {
+		pool += 26
+	}
+	if classes.lower {
+		pool += 26
+	}
+	if classes.digit {
+		pool += 10
+	}
+	if classes.symbol {
+		pool += 33
+	}
+	if classes.other {
+		pool += 100
+	}
+	if pool == 0 {
+		return 0
+	}
+
+	length := 0
+	var previous rune
+	for i, r := range []rune(password) {
+		if i > 0 && r == previous {
+			continue
+		}
+		length++
+		previous = r
+	}
+
+	return float64(length) * math.Log2(float64(pool))
+}
+
+func containsIdentity(password string, identity PasswordIdentity) bool {
+	password = strings.ToLower(password)
+
+	candidates := []string{identity.Username, identity.Email}
+	localPart, _, found := strings.Cut(identity.Email, "@")
+	if found {
+		candidates = append(candidates, localPart)
+	}
+
+	for _, candidate := range candidates {
+		candidate = strings.ToLower(strings.TrimSpace(candidate))
+		if len(candidate) >= 3 && strings.Contains(password, candidate) {
+			return true
+		}
+	}
+	return false
+}
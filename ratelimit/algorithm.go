@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// takeToken refills the bucket at Limit tokens per Window, up to Limit, and
+// takes one token.
+func takeToken(state State, found bool, rule Rule, now time.Time) (State, Result) {
+	limit := float64(rule.Limit)
+	rate := limit / rule.Window.Seconds()
+
+	tokens := limit
+	if found {
+		elapsed := now.Sub(state.UpdatedAt).Seconds()
+		tokens = math.Min(limit, state.Value+math.Max(elapsed, 0)*rate)
+	}
+
+	result := Result{Limit: rule.Limit}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	result.Remaining = int(math.Floor(tokens))
+	result.Reset = seconds((limit - tokens) / rate)
+
+	return State{
+		Value:     tokens,
+		UpdatedAt: now,
+		ExpiresAt: now.Add(result.Reset),
+	}, result
+}
+
+// countInWindow approximates a sliding window from fixed windows: the count
+// of the previous window is weighted by how much of it still overlaps the
+// last Window.
+func countInWindow(state State, found bool, rule Rule, now time.Time) (State, Result) {
+	limit := float64(rule.Limit)
+	windowStart := now.Truncate(rule.Window)
+
+	current, previous := 0.0, 0.0
+	if found {
+		switch {
+		case state.UpdatedAt.Equal(windowStart):
+			current, previous = state.Value, state.Previous
+		case state.UpdatedAt.Equal(windowStart.Add(-rule.Window)):
+			previous = state.Value
+		}
+	}
+
+	elapsed := now.Sub(windowStart)
+	weight := 1 - elapsed.Seconds()/rule.Window.Seconds()
+
+	result := Result{Limit: rule.Limit}
+	if previous*weight+current+1 <= limit {
+		current++
+		result.Allowed = true
+	} else {
+		result.RetryAfter = windowRetryAfter(current, previous, rule, elapsed)
+	}
+	result.Remaining = int(math.Max(math.Floor(limit-previous*weight-current), 0))
+	result.Reset = rule.Window - elapsed
+
+	return State{
+		Value:     current,
+		Previous:  previous,
+		UpdatedAt: windowStart,
+		ExpiresAt: windowStart.Add(2 * rule.Window),
+	}, result
+}
+
+// windowRetryAfter is the time until the weighted count leaves room for one
+// more request, either later in this window or in the next one.
+func windowRetryAfter(current float64, previous float64, rule Rule, elapsed time.Duration) time.Duration {
+	limit := float64(rule.Limit)
+	window := rule.Window.Seconds()
+
+	if current+1 <= limit && previous > 0 {
+		weight := (limit - current - 1) / previous
+		return seconds(window*(1-weight) - elapsed.Seconds())
+	}
+
+	// In the next window this window's count becomes the previous one.
+	weight := math.Max(limit-1, 0) / current
+	return seconds(window - elapsed.Seconds() + window*(1-math.Min(weight, 1)))
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(math.Max(value, 0) * float64(time.Second))
+}
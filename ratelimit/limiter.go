@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	AlgorithmTokenBucket   = "token_bucket"
+	AlgorithmSlidingWindow = "sliding_window"
+)
+
+// Keys a rule can count requests by.
+const (
+	KeyIP      = "ip"
+	KeyAccount = "account"
+	KeyClient  = "client"
+)
+
+// Rule allows Limit requests per Window for each distinct Key value, e.g.
+// 5 login attempts per minute per account.
+type Rule struct {
+	Key    string
+	Limit  int
+	Window time.Duration
+}
+
+// Config holds the algorithm and the rules of each throttled route.
+type Config struct {
+	Algorithm    string
+	Login        []Rule
+	Register     []Rule
+	RefreshToken []Rule
+}
+
+// Result describes the quota after a request. Reset is the time until the
+// quota is fully available again; RetryAfter is only set when the request
+// was refused.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+type Limiter struct {
+	Store     Store
+	Algorithm string
+}
+
+func NewLimiter(store Store, algorithm string) *Limiter {
+	return &Limiter{
+		Store:     store,
+		Algorithm: algorithm,
+	}
+}
+
+// Allow counts one request against rule for key.
+func (limiter *Limiter) Allow(ctx context.Context, rule Rule, key string) (Result, error) {
+	var result Result
+	err := limiter.Store.Update(ctx, key, func(state State, found bool) State {
+		now := time.Now()
+		if !found {
+			state = State{}
+		}
+
+		if limiter.Algorithm == AlgorithmTokenBucket {
+			state, result = takeToken(state, found, rule, now)
+		} else {
+			state, result = countInWindow(state, found, rule, now)
+		}
+		return state
+	})
+	return result, err
+}
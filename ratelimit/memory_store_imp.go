@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryStoreImpl counts per process, so with several instances each one
+// enforces the limits on its own.
+type memoryStoreImpl struct {
+	mutex     sync.Mutex
+	states    map[string]State
+	lastSweep time.Time
+}
+
+const sweepInterval = time.Minute
+
+func NewMemoryStore() Store {
+	return &memoryStoreImpl{
+		states: map[string]State{},
+	}
+}
+
+func (store *memoryStoreImpl) Update(ctx context.Context, key string, update func(state State, found bool) State) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(store.lastSweep) >= sweepInterval {
+		store.deleteExpired(now)
+		store.lastSweep = now
+	}
+
+	state, found := store.states[key]
+	store.states[key] = update(state, found && state.ExpiresAt.After(now))
+	return nil
+}
+
+func (store *memoryStoreImpl) DeleteExpired(ctx context.Context) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.deleteExpired(time.Now())
+	return nil
+}
+
+func (store *memoryStoreImpl) deleteExpired(now time.Time) {
+	for key, state := range store.states {
+		if !state.ExpiresAt.After(now) {
+			delete(store.states, key)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// postgresStoreImpl shares the counters between instances. Each update runs
+// in its own transaction and locks the row, so concurrent requests for the
+// same key are counted one after the other. Times are stored in UTC because
+// the columns have no time zone.
+type postgresStoreImpl struct {
+	DB *sql.DB
+}
+
+func NewPostgresStore(db *sql.DB) Store {
+	return &postgresStoreImpl{
+		DB: db,
+	}
+}
+
+func (store *postgresStoreImpl) Update(ctx context.Context, key string, update func(state State, found bool) State) (err error) {
+	tx, err := store.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	// The placeholder row is already expired, so it reads as not found.
+	SQL := "INSERT INTO rate_limits (key, value, previous, updated_at, expires_at) VALUES ($1, 0, 0, $2, $2) ON CONFLICT (key) DO NOTHING"
+	_, err = tx.ExecContext(ctx, SQL, key, time.Unix(0, 0).UTC())
+	if err != nil {
+		return err
+	}
+
+	SQL = "SELECT value, previous, updated_at, expires_at FROM rate_limits WHERE key = $1 FOR UPDATE"
+	state := State{}
+	err = tx.QueryRowContext(ctx, SQL, key).Scan(&state.Value, &state.Previous, &state.UpdatedAt, &state.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	state = update(state, state.ExpiresAt.After(time.Now()))
+
+	SQL = "UPDATE rate_limits SET value = $2, previous = $3, updated_at = $4, expires_at = $5 WHERE key = $1"
+	_, err = tx.ExecContext(ctx, SQL, key, state.Value, state.Previous, state.UpdatedAt.UTC(), state.ExpiresAt.UTC())
+	return err
+}
+
+func (store *postgresStoreImpl) DeleteExpired(ctx context.Context) error {
+	SQL := "DELETE FROM rate_limits WHERE expires_at < $1"
+	_, err := store.DB.ExecContext(ctx, SQL, time.Now().UTC())
+	return err
+}
@@ -0,0 +1,27 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// State is what an algorithm keeps per key. The token bucket stores the
+// tokens left in Value; the sliding window stores the counts of the current
+// and previous window in Value and Previous, with UpdatedAt as the start of
+// the current window.
+type State struct {
+	Value     float64
+	Previous  float64
+	UpdatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Store keeps the counters. Update must apply the function atomically with
+// respect to other Updates of the same key, across instances if the store
+// is shared.
+type Store interface {
+	// Update replaces the state under key with the one returned by update.
+	// Missing or expired entries are passed with found set to false.
+	Update(ctx context.Context, key string, update func(state State, found bool) State) error
+	DeleteExpired(ctx context.Context) error
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"golang_jwt/model/domain"
+)
+
+type AccountUnlockRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, accountUnlock domain.AccountUnlock) domain.AccountUnlock
+	FindByTokenHash(ctx context.Context, tx *sql.Tx, tokenHash string) (domain.AccountUnlock, error)
+	MarkUsed(ctx context.Context, tx *sql.Tx, id int) error
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"golang_jwt/helper"
+	"golang_jwt/model/domain"
+)
+
+type accountUnlockRepositoryImpl struct {
+}
+
+func NewAccountUnlockRepository() AccountUnlockRepository {
+	return &accountUnlockRepositoryImpl{}
+}
+
+func (repository *accountUnlockRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, accountUnlock domain.AccountUnlock) domain.AccountUnlock {
+	SQL := "INSERT INTO account_unlocks (user_id, token_hash, expires_at) VALUES ($1, $2, $3) RETURNING id, created_at"
+	err := tx.QueryRowContext(ctx, SQL, accountUnlock.UserID, accountUnlock.TokenHash, accountUnlock.ExpiresAt).Scan(&accountUnlock.ID, &accountUnlock.CreatedAt)
+	helper.ErrorConditionCheck(err)
+	return accountUnlock
+}
+
+func (repository *accountUnlockRepositoryImpl) FindByTokenHash(ctx context.Context, tx *sql.Tx, tokenHash string) (domain.AccountUnlock, error) {
+	SQL := "SELECT id, user_id, token_hash, expires_at, used_at, created_at FROM account_unlocks WHERE token_hash = $1 FOR UPDATE"
+	row := tx.QueryRowContext(ctx, SQL, tokenHash)
+
+	accountUnlock := domain.AccountUnlock{}
+	err := row.Scan(&accountUnlock.ID, &accountUnlock.UserID, &accountUnlock.TokenHash, &accountUnlock.ExpiresAt, &accountUnlock.UsedAt, &accountUnlock.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return accountUnlock, errors.New("account unlock not found")
+		}
+		return accountUnlock, err
+	}
+	return accountUnlock, nil
+}
+
+func (repository *accountUnlockRepositoryImpl) MarkUsed(ctx context.Context, tx *sql.Tx, id int) error {
+	SQL := "UPDATE account_unlocks SET used_at = NOW() WHERE id = $1"
+	_, err := tx.ExecContext(ctx, SQL, id)
+	helper.ErrorConditionCheck(err)
+	return nil
+}
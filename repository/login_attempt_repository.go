@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"golang_jwt/model/domain"
+	"time"
+)
+
+type LoginAttemptRepository interface {
+	Find(ctx context.Context, tx *sql.Tx, keyType string, key string) (domain.LoginAttempt, error)
+	RecordFailure(ctx context.Context, tx *sql.Tx, keyType string, key string, window time.Duration) domain.LoginAttempt
+	Lock(ctx context.Context, tx *sql.Tx, keyType string, key string, until time.Time) error
+	Delete(ctx context.Context, tx *sql.Tx, keyType string, key string) error
+}
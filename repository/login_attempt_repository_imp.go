@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"golang_jwt/helper"
+	"golang_jwt/model/domain"
+	"time"
+)
+
+type loginAttemptRepositoryImpl struct {
+}
+
+func NewLoginAttemptRepository() LoginAttemptRepository {
+	return &loginAttemptRepositoryImpl{}
+}
+
+func (repository *loginAttemptRepositoryImpl) Find(ctx context.Context, tx *sql.Tx, keyType string, key string) (domain.LoginAttempt, error) {
+	SQL := "SELECT key_type, key, failures, last_failure_at, locked_until FROM login_attempts WHERE key_type = $1 AND key = $2"
+	row := tx.QueryRowContext(ctx, SQL, keyType, key)
+
+	loginAttempt := domain.LoginAttempt{}
+	err := row.Scan(&loginAttempt.KeyType, &loginAttempt.Key, &loginAttempt.Failures, &loginAttempt.LastFailureAt, &loginAttempt.LockedUntil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return loginAttempt, errors.New("login attempt not found")
+		}
+		return loginAttempt, err
+	}
+	return loginAttempt, nil
+}
+
+// RecordFailure increments the failure counter, starting again from one when
+// the previous failure is older than window.
+func (repository *loginAttemptRepositoryImpl) RecordFailure(ctx context.Context, tx *sql.Tx, keyType string, key string, window time.Duration) domain.LoginAttempt {
+	SQL := `INSERT INTO login_attempts (key_type, key, failures, last_failure_at) VALUES ($1, $2, 1, NOW())
+		ON CONFLICT (key_type, key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failure_at < NOW() - make_interval(secs => $3) THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failure_at = NOW()
+		RETURNING key_type, key, failures, last_failure_at, locked_until`
+	row := tx.QueryRowContext(ctx, SQL, keyType, key, window.Seconds())
+
+	loginAttempt := domain.LoginAttempt{}
+	err := row.Scan(&loginAttempt.KeyType, &loginAttempt.Key, &loginAttempt.Failures, &loginAttempt.LastFailureAt, &loginAttempt.LockedUntil)
+	helper.ErrorConditionCheck(err)
+	return loginAttempt
+}
+
+func (repository *loginAttemptRepositoryImpl) Lock(ctx context.Context, tx *sql.Tx, keyType string, key string, until time.Time) error {
+	SQL := "UPDATE login_attempts SET locked_until = $1 WHERE key_type = $2 AND key = $3"
+	_, err := tx.ExecContext(ctx, SQL, until, keyType, key)
+	helper.ErrorConditionCheck(err)
+	return nil
+}
+
+func (repository *loginAttemptRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, keyType string, key string) error {
+	SQL := "DELETE FROM login_attempts WHERE key_type = $1 AND key = $2"
+	_, err := tx.ExecContext(ctx, SQL, keyType, key)
+	helper.ErrorConditionCheck(err)
+	return nil
+}
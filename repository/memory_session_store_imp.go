@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"golang_jwt/model/domain"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memorySessionStoreImpl keeps sessions in process memory, for tests and
+// single-instance deployments. Sessions are lost on restart.
+type memorySessionStoreImpl struct {
+	mutex     sync.RWMutex
+	sessions  map[string]domain.Session
+	lastSweep time.Time
+}
+
+// sweepInterval limits how often Create drops expired sessions, so the
+// store stays bounded even when the cleanup scheduler runs rarely.
+const sweepInterval = time.Minute
+
+func NewMemorySessionStore() SessionStore {
+	return &memorySessionStoreImpl{
+		sessions: map[string]domain.Session{},
+	}
+}
+
+func (store *memorySessionStoreImpl) Create(ctx context.Context, session domain.Session) domain.Session {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(store.lastSweep) >= sweepInterval {
+		store.deleteExpired(now)
+		store.lastSweep = now
+	}
+
+	session.Created_At = now
+	session.Last_Used_At = now
+	store.sessions[session.ID] = session
+	return session
+}
+
+// Get also returns expired sessions that have not been swept yet, like the
+// Postgres store; callers check the deadlines themselves.
+func (store *memorySessionStoreImpl) Get(ctx context.Context, id string) (domain.Session, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	session, ok := store.sessions[id]
+	if !ok {
+		return session, errors.New("session not found")
+	}
+	return session, nil
+}
+
+func (store *memorySessionStoreImpl) FindByEmail(ctx context.Context, email string) []domain.Session {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	now := time.Now()
+	var sessions []domain.Session
+	for _, session := range store.sessions {
+		if session.User_Email == email && !session.Is_Revoked && session.Expires_At.After(now) {
+			sessions = append(sessions, session)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Last_Used_At.After(sessions[j].Last_Used_At)
+	})
+	return sessions
+}
+
+func (store *memorySessionStoreImpl) UpdateActivity(ctx context.Context, session domain.Session) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	stored, ok := store.sessions[session.ID]
+	if !ok {
+		return nil
+	}
+	stored.Ip_Address = session.Ip_Address
+	stored.User_Agent = session.User_Agent
+	stored.Device_Label = session.Device_Label
+	stored.Fingerprint = session.Fingerprint
+	stored.Expires_At = session.Expires_At
+	stored.Last_Used_At = time.Now()
+	store.sessions[session.ID] = stored
+	return nil
+}
+
+func (store *memorySessionStoreImpl) Revoke(ctx context.Context, id string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if session, ok := store.sessions[id]; ok {
+		session.Is_Revoked = true
+		store.sessions[id] = session
+	}
+	return nil
+}
+
+func (store *memorySessionStoreImpl) RevokeForUser(ctx context.Context, id string, email string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	session, ok := store.sessions[id]
+	if !ok || session.User_Email != email || session.Is_Revoked {
+		return errors.New("session not found")
+	}
+	session.Is_Revoked = true
+	store.sessions[id] = session
+	return nil
+}
+
+func (store *memorySessionStoreImpl) RevokeAllForUser(ctx context.Context, email string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for id, session := range store.sessions {
+		if session.User_Email == email {
+			session.Is_Revoked = true
+			store.sessions[id] = session
+		}
+	}
+	return nil
+}
+
+func (store *memorySessionStoreImpl) Delete(ctx context.Context, id string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	delete(store.sessions, id)
+	return nil
+}
+
+func (store *memorySessionStoreImpl) DeleteAllForUser(ctx context.Context, email string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for id, session := range store.sessions {
+		if session.User_Email == email {
+			delete(store.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (store *memorySessionStoreImpl) DeleteExpired(ctx context.Context) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.deleteExpired(time.Now())
+	return nil
+}
+
+func (store *memorySessionStoreImpl) deleteExpired(now time.Time) {
+	for id, session := range store.sessions {
+		if session.Expires_At.Before(now) {
+			delete(store.sessions, id)
+		}
+	}
+}
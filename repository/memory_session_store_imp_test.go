@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"golang_jwt/model/domain"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newSession(id string, email string, expiresAt time.Time) domain.Session {
+	return domain.Session{
+		ID:                  id,
+		User_Email:          email,
+		Refresh_Token:       "token-" + id,
+		Expires_At:          expiresAt,
+		Absolute_Expires_At: expiresAt,
+	}
+}
+
+func TestMemorySessionStoreConcurrentCreate(t *testing.T) {
+	store := NewMemorySessionStore()
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+
+	const count = 100
+	var wait sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wait.Add(1)
+		go func(i int) {
+			defer wait.Done()
+			session := store.Create(ctx, newSession(strconv.Itoa(i), "arthur@example.com", expiresAt))
+			store.UpdateActivity(ctx, session)
+			store.FindByEmail(ctx, "arthur@example.com")
+		}(i)
+	}
+	wait.Wait()
+
+	sessions := store.FindByEmail(ctx, "arthur@example.com")
+	if len(sessions) != count {
+		t.Fatalf("FindByEmail returned %d sessions, want %d", len(sessions), count)
+	}
+	for i := 0; i < count; i++ {
+		session, err := store.Get(ctx, strconv.Itoa(i))
+		if err != nil {
+			t.Fatalf("Get(%d) returned error: %v", i, err)
+		}
+		if session.Created_At.IsZero() || session.Last_Used_At.IsZero() {
+			t.Errorf("session %d = %+v, want creation and last use times", i, session)
+		}
+	}
+}
+
+func TestMemorySessionStoreRevoke(t *testing.T) {
+	store := NewMemorySessionStore()
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+	store.Create(ctx, newSession("1", "arthur@example.com", expiresAt))
+	store.Create(ctx, newSession("2", "arthur@example.com", expiresAt))
+	store.Create(ctx, newSession("3", "ford@example.com", expiresAt))
+
+	store.Revoke(ctx, "1")
+	session, err := store.Get(ctx, "1")
+	if err != nil || !session.Is_Revoked {
+		t.Errorf("Get(1) = %+v, %v; want a revoked session", session, err)
+	}
+	if sessions := store.FindByEmail(ctx, "arthur@example.com"); len(sessions) != 1 || sessions[0].ID != "2" {
+		t.Errorf("FindByEmail = %+v, want only session 2", sessions)
+	}
+
+	if err := store.RevokeForUser(ctx, "3", "arthur@example.com"); err == nil {
+		t.Error("RevokeForUser revoked a session of another user")
+	}
+	if err := store.RevokeForUser(ctx, "1", "arthur@example.com"); err == nil {
+		t.Error("RevokeForUser revoked an already revoked session")
+	}
+	if err := store.RevokeForUser(ctx, "2", "arthur@example.com"); err != nil {
+		t.Errorf("RevokeForUser returned error: %v", err)
+	}
+
+	store.RevokeAllForUser(ctx, "ford@example.com")
+	if sessions := store.FindByEmail(ctx, "ford@example.com"); len(sessions) != 0 {
+		t.Errorf("FindByEmail after RevokeAllForUser = %+v, want none", sessions)
+	}
+}
+
+func TestMemorySessionStoreConcurrentRevoke(t *testing.T) {
+	store := NewMemorySessionStore()
+	ctx := context.Background()
+	store.Create(ctx, newSession("1", "arthur@example.com", time.Now().Add(time.Hour)))
+
+	var revoked int
+	var mutex sync.Mutex
+	var wait sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			if store.RevokeForUser(ctx, "1", "arthur@example.com") == nil {
+				mutex.Lock()
+				revoked++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wait.Wait()
+
+	if revoked != 1 {
+		t.Errorf("RevokeForUser succeeded %d times, want once", revoked)
+	}
+}
+
+func TestMemorySessionStoreEvictsExpiredSessions(t *testing.T) {
+	store := NewMemorySessionStore().(*memorySessionStoreImpl)
+	ctx := context.Background()
+	now := time.Now()
+	store.Create(ctx, newSession("expired", "arthur@example.com", now.Add(-time.Minute)))
+	store.Create(ctx, newSession("active", "arthur@example.com", now.Add(time.Hour)))
+
+	// Expired sessions are still returned until they are swept, but never
+	// listed as active.
+	if _, err := store.Get(ctx, "expired"); err != nil {
+		t.Errorf("Get(expired) before the sweep returned error: %v", err)
+	}
+	if sessions := store.FindByEmail(ctx, "arthur@example.com"); len(sessions) != 1 || sessions[0].ID != "active" {
+		t.Errorf("FindByEmail = %+v, want only the active session", sessions)
+	}
+
+	store.DeleteExpired(ctx)
+	if _, err := store.Get(ctx, "expired"); err == nil {
+		t.Error("Get(expired) after DeleteExpired found the session")
+	}
+	if _, err := store.Get(ctx, "active"); err != nil {
+		t.Errorf("Get(active) after DeleteExpired returned error: %v", err)
+	}
+
+	store.Create(ctx, newSession("stale", "arthur@example.com", now.Add(-time.Minute)))
+	store.lastSweep = now.Add(-sweepInterval)
+	store.Create(ctx, newSession("new", "arthur@example.com", now.Add(time.Hour)))
+	if _, err := store.Get(ctx, "stale"); err == nil {
+		t.Error("Create did not sweep the expired session once the sweep interval passed")
+	}
+}
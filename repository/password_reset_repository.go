@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"golang_jwt/model/domain"
+)
+
+type PasswordResetRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, passwordReset domain.PasswordReset) domain.PasswordReset
+	FindByTokenHash(ctx context.Context, tx *sql.Tx, tokenHash string) (domain.PasswordReset, error)
+	MarkUsed(ctx context.Context, tx *sql.Tx, id int) error
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"golang_jwt/helper"
+	"golang_jwt/model/domain"
+)
+
+type passwordResetRepositoryImpl struct {
+}
+
+func NewPasswordResetRepository() PasswordResetRepository {
+	return &passwordResetRepositoryImpl{}
+}
+
+func (repository *passwordResetRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, passwordReset domain.PasswordReset) domain.PasswordReset {
+	SQL := "INSERT INTO password_resets (user_id, token_hash, expires_at) VALUES ($1, $2, $3) RETURNING id, created_at"
+	err := tx.QueryRowContext(ctx, SQL, passwordReset.UserID, passwordReset.TokenHash, passwordReset.ExpiresAt).Scan(&passwordReset.ID, &passwordReset.CreatedAt)
+	helper.ErrorConditionCheck(err)
+	return passwordReset
+}
+
+func (repository *passwordResetRepositoryImpl) FindByTokenHash(ctx context.Context, tx *sql.Tx, tokenHash string) (domain.PasswordReset, error) {
+	SQL := "SELECT id, user_id, token_hash, expires_at, used_at, created_at FROM password_resets WHERE token_hash = $1 FOR UPDATE"
+	row := tx.QueryRowContext(ctx, SQL, tokenHash)
+
+	passwordReset := domain.PasswordReset{}
+	err := row.Scan(&passwordReset.ID, &passwordReset.UserID, &passwordReset.TokenHash, &passwordReset.ExpiresAt, &passwordReset.UsedAt, &passwordReset.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return passwordReset, errors.New("password reset not found")
+		}
+		return passwordReset, err
+	}
+	return passwordReset, nil
+}
+
+func (repository *passwordResetRepositoryImpl) MarkUsed(ctx context.Context, tx *sql.Tx, id int) error {
+	SQL := "UPDATE password_resets SET used_at = NOW() WHERE id = $1"
+	_, err := tx.ExecContext(ctx, SQL, id)
+	helper.ErrorConditionCheck(err)
+	return nil
+}
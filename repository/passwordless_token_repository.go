@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"golang_jwt/model/domain"
+	"time"
+)
+
+type PasswordlessTokenRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, passwordlessToken domain.PasswordlessToken) domain.PasswordlessToken
+	FindByTokenHash(ctx context.Context, tx *sql.Tx, tokenHash string) (domain.PasswordlessToken, error)
+	FindActiveCode(ctx context.Context, tx *sql.Tx, email string) (domain.PasswordlessToken, error)
+	IncrementAttempts(ctx context.Context, tx *sql.Tx, id int) error
+	MarkUsed(ctx context.Context, tx *sql.Tx, id int) error
+	InvalidateActive(ctx context.Context, tx *sql.Tx, email string, method string) error
+	CountSince(ctx context.Context, tx *sql.Tx, email string, since time.Time) (count int, oldest time.Time)
+}
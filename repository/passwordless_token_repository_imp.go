@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"golang_jwt/helper"
+	"golang_jwt/model/domain"
+	"time"
+)
+
+type passwordlessTokenRepositoryImpl struct {
+}
+
+func NewPasswordlessTokenRepository() PasswordlessTokenRepository {
+	return &passwordlessTokenRepositoryImpl{}
+}
+
+const passwordlessTokenColumns = "id, email, user_id, method, token_hash, attempts, expires_at, used_at, created_at"
+
+func scanPasswordlessToken(row rowScanner) (domain.PasswordlessToken, error) {
+	passwordlessToken := domain.PasswordlessToken{}
+	err := row.Scan(&passwordlessToken.ID, &passwordlessToken.Email, &passwordlessToken.UserID, &passwordlessToken.Method, &passwordlessToken.TokenHash, &passwordlessToken.Attempts, &passwordlessToken.ExpiresAt, &passwordlessToken.UsedAt, &passwordlessToken.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return passwordlessToken, errors.New("passwordless token not found")
+		}
+		return passwordlessToken, err
+	}
+	return passwordlessToken, nil
+}
+
+// Save also clears tokens that expired more than a day ago; they no longer
+// count towards the rate limit.
+func (repository *passwordlessTokenRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, passwordlessToken domain.PasswordlessToken) domain.PasswordlessToken {
+	_, err := tx.ExecContext(ctx, "DELETE FROM passwordless_tokens WHERE expires_at < NOW() - INTERVAL '1 day'")
+	helper.ErrorConditionCheck(err)
+
+	SQL := "INSERT INTO passwordless_tokens (email, user_id, method, token_hash, expires_at) VALUES (lower($1), $2, $3, $4, $5) RETURNING id, created_at"
+	err = tx.QueryRowContext(ctx, SQL, passwordlessToken.Email, passwordlessToken.UserID, passwordlessToken.Method, passwordlessToken.TokenHash, passwordlessToken.ExpiresAt).Scan(&passwordlessToken.ID, &passwordlessToken.CreatedAt)
+	helper.ErrorConditionCheck(err)
+	return passwordlessToken
+}
+
+func (repository *passwordlessTokenRepositoryImpl) FindByTokenHash(ctx context.Context, tx *sql.Tx, tokenHash string) (domain.PasswordlessToken, error) {
+	SQL := "SELECT " + passwordlessTokenColumns + " FROM passwordless_tokens WHERE token_hash = $1 AND method = 'link' FOR UPDATE"
+	row := tx.QueryRowContext(ctx, SQL, tokenHash)
+	return scanPasswordlessToken(row)
+}
+
+// FindActiveCode returns the newest unused code for the address; requesting
+// a new code invalidates the older ones.
+func (repository *passwordlessTokenRepositoryImpl) FindActiveCode(ctx context.Context, tx *sql.Tx, email string) (domain.PasswordlessToken, error) {
+	SQL := "SELECT " + passwordlessTokenColumns + " FROM passwordless_tokens WHERE email = lower($1) AND method = 'code' AND used_at IS NULL AND expires_at > NOW() ORDER BY created_at DESC LIMIT 1 FOR UPDATE"
+	row := tx.QueryRowContext(ctx, SQL, email)
+	return scanPasswordlessToken(row)
+}
+
+func (repository *passwordlessTokenRepositoryImpl) IncrementAttempts(ctx context.Context, tx *sql.Tx, id int) error {
+	SQL := "UPDATE passwordless_tokens SET attempts = attempts + 1 WHERE id = $1"
+	_, err := tx.ExecContext(ctx, SQL, id)
+	helper.ErrorConditionCheck(err)
+	return nil
+}
+
+func (repository *passwordlessTokenRepositoryImpl) MarkUsed(ctx context.Context, tx *sql.Tx, id int) error {
+	SQL := "UPDATE passwordless_tokens SET used_at = NOW() WHERE id = $1"
+	_, err := tx.ExecContext(ctx, SQL, id)
+	helper.ErrorConditionCheck(err)
+	return nil
+}
+
+func (repository *passwordlessTokenRepositoryImpl) InvalidateActive(ctx context.Context, tx *sql.Tx, email string, method string) error {
+	SQL := "UPDATE passwordless_tokens SET used_at = NOW() WHERE email = lower($1) AND method = $2 AND used_at IS NULL"
+	_, err := tx.ExecContext(ctx, SQL, email, method)
+	helper.ErrorConditionCheck(err)
+	return nil
+}
+
+func (repository *passwordlessTokenRepositoryImpl) CountSince(ctx context.Context, tx *sql.Tx, email string, since time.Time) (int, time.Time) {
+	SQL := "SELECT COUNT(*), COALESCE(MIN(created_at), NOW()) FROM passwordless_tokens WHERE email = lower($1) AND created_at > $2"
+
+	var count int
+	var oldest time.Time
+	err := tx.QueryRowContext(ctx, SQL, email, since).Scan(&count, &oldest)
+	helper.ErrorConditionCheck(err)
+	return count, oldest
+}
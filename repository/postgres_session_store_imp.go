@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"golang_jwt/helper"
+	"golang_jwt/model/domain"
+)
+
+// postgresSessionStoreImpl runs every statement on its own, outside of the
+// caller's transaction, so it behaves like the in-memory store.
+type postgresSessionStoreImpl struct {
+	DB *sql.DB
+}
+
+func NewPostgresSessionStore(db *sql.DB) SessionStore {
+	return &postgresSessionStoreImpl{
+		DB: db,
+	}
+}
+
+const sessionColumns = "id, user_email, refresh_token, is_revoked, created_at, expires_at, absolute_expires_at, ip_address, user_agent, device_label, fingerprint, last_used_at"
+
+func scanSession(row rowScanner) (domain.Session, error) {
+	session := domain.Session{}
+	err := row.Scan(&session.ID, &session.User_Email, &session.Refresh_Token, &session.Is_Revoked, &session.Created_At, &session.Expires_At, &session.Absolute_Expires_At, &session.Ip_Address, &session.User_Agent, &session.Device_Label, &session.Fingerprint, &session.Last_Used_At)
+	return session, err
+}
+
+func (store *postgresSessionStoreImpl) Create(ctx context.Context, session domain.Session) domain.Session {
+	SQL := "INSERT INTO sessions (id, user_email, refresh_token, is_revoked, expires_at, absolute_expires_at, ip_address, user_agent, device_label, fingerprint) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING created_at, last_used_at"
+	err := store.DB.QueryRowContext(ctx, SQL, session.ID, session.User_Email, session.Refresh_Token, session.Is_Revoked, session.Expires_At, session.Absolute_Expires_At, session.Ip_Address, session.User_Agent, session.Device_Label, session.Fingerprint).Scan(&session.Created_At, &session.Last_Used_At)
+	helper.ErrorConditionCheck(err)
+	return session
+}
+
+func (store *postgresSessionStoreImpl) Get(ctx context.Context, id string) (domain.Session, error) {
+	SQL := "SELECT " + sessionColumns + " FROM sessions WHERE id = $1"
+	row := store.DB.QueryRowContext(ctx, SQL, id)
+
+	session, err := scanSession(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return session, errors.New("session not found")
+		}
+		return session, err
+	}
+	return session, nil
+}
+
+func (store *postgresSessionStoreImpl) FindByEmail(ctx context.Context, email string) []domain.Session {
+	SQL := "SELECT " + sessionColumns + " FROM sessions WHERE user_email = $1 AND is_revoked = false AND expires_at > NOW() ORDER BY last_used_at DESC"
+	rows, err := store.DB.QueryContext(ctx, SQL, email)
+	helper.ErrorConditionCheck(err)
+	defer rows.Close()
+
+	var sessions []domain.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		helper.ErrorConditionCheck(err)
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+func (store *postgresSessionStoreImpl) UpdateActivity(ctx context.Context, session domain.Session) error {
+	SQL := "UPDATE sessions SET ip_address = $1, user_agent = $2, device_label = $3, fingerprint = $4, expires_at = $5, last_used_at = NOW() WHERE id = $6"
+	_, err := store.DB.ExecContext(ctx, SQL, session.Ip_Address, session.User_Agent, session.Device_Label, session.Fingerprint, session.Expires_At, session.ID)
+	helper.ErrorConditionCheck(err)
+	return nil
+}
+
+func (store *postgresSessionStoreImpl) Revoke(ctx context.Context, id string) error {
+	SQL := "UPDATE sessions SET is_revoked = true WHERE id = $1"
+	_, err := store.DB.ExecContext(ctx, SQL, id)
+	helper.ErrorConditionCheck(err)
+	return nil
+}
+
+func (store *postgresSessionStoreImpl) RevokeForUser(ctx context.Context, id string, email string) error {
+	SQL := "UPDATE sessions SET is_revoked = true WHERE id = $1 AND user_email = $2 AND is_revoked = false"
+	result, err := store.DB.ExecContext(ctx, SQL, id, email)
+	helper.ErrorConditionCheck(err)
+
+	affected, err := result.RowsAffected()
+	helper.ErrorConditionCheck(err)
+	if affected == 0 {
+		return errors.New("session not found")
+	}
+	return nil
+}
+
+func (store *postgresSessionStoreImpl) RevokeAllForUser(ctx context.Context, email string) error {
+	SQL := "UPDATE sessions SET is_revoked = true WHERE user_email = $1"
+	_, err := store.DB.ExecContext(ctx, SQL, email)
+	helper.ErrorConditionCheck(err)
+	return nil
+}
+
+func (store *postgresSessionStoreImpl) Delete(ctx context.Context, id string) error {
+	SQL := "DELETE FROM sessions WHERE id = $1"
+	_, err := store.DB.ExecContext(ctx, SQL, id)
+	helper.ErrorConditionCheck(err)
+	return nil
+}
+
+func (store *postgresSessionStoreImpl) DeleteAllForUser(ctx context.Context, email string) error {
+	SQL := "DELETE FROM sessions WHERE user_email = $1"
+	_, err := store.DB.ExecContext(ctx, SQL, email)
+	helper.ErrorConditionCheck(err)
+	return nil
+}
+
+func (store *postgresSessionStoreImpl) DeleteExpired(ctx context.Context) error {
+	SQL := "DELETE FROM sessions WHERE expires_at < NOW()"
+	_, err := store.DB.ExecContext(ctx, SQL)
+	helper.ErrorConditionCheck(err)
+	return nil
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+type RecoveryCodeRepository interface {
+	ReplaceAll(ctx context.Context, tx *sql.Tx, userId int, codeHashes []string) error
+	Consume(ctx context.Context, tx *sql.Tx, userId int, codeHash string) error
+	Count(ctx context.Context, tx *sql.Tx, userId int) (total int, remaining int)
+	DeleteByUserId(ctx context.Context, tx *sql.Tx, userId int) error
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"golang_jwt/helper"
+)
+
+type recoveryCodeRepositoryImpl struct {
+}
+
+func NewRecoveryCodeRepository() RecoveryCodeRepository {
+	return &recoveryCodeRepositoryImpl{}
+}
+
+// ReplaceAll removes every previous code, used or not, so only the newest
+// set is ever valid.
+func (repository *recoveryCodeRepositoryImpl) ReplaceAll(ctx context.Context, tx *sql.Tx, userId int, codeHashes []string) error {
+	err := repository.DeleteByUserId(ctx, tx, userId)
+	helper.ErrorConditionCheck(err)
+
+	SQL := "INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)"
+	for _, codeHash := range codeHashes {
+		_, err := tx.ExecContext(ctx, SQL, userId, codeHash)
+		helper.ErrorConditionCheck(err)
+	}
+	return nil
+}
+
+func (repository *recoveryCodeRepositoryImpl) Consume(ctx context.Context, tx *sql.Tx, userId int, codeHash string) error {
+	SQL := "UPDATE recovery_codes SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL"
+	result, err := tx.ExecContext(ctx, SQL, userId, codeHash)
+	helper.ErrorConditionCheck(err)
+
+	affected, err := result.RowsAffected()
+	helper.ErrorConditionCheck(err)
+	if affected == 0 {
+		return errors.New("recovery code not found")
+	}
+	return nil
+}
+
+func (repository *recoveryCodeRepositoryImpl) Count(ctx context.Context, tx *sql.Tx, userId int) (int, int) {
+	SQL := "SELECT COUNT(*), COUNT(*) FILTER (WHERE used_at IS NULL) FROM recovery_codes WHERE user_id = $1"
+
+	var total, remaining int
+	err := tx.QueryRowContext(ctx, SQL, userId).Scan(&total, &remaining)
+	helper.ErrorConditionCheck(err)
+	return total, remaining
+}
+
+func (repository *recoveryCodeRepositoryImpl) DeleteByUserId(ctx context.Context, tx *sql.Tx, userId int) error {
+	SQL := "DELETE FROM recovery_codes WHERE user_id = $1"
+	_, err := tx.ExecContext(ctx, SQL, userId)
+	helper.ErrorConditionCheck(err)
+	return nil
+}
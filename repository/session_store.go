@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"golang_jwt/model/domain"
+)
+
+// SessionStore keeps login sessions, keyed by id and listed by the owner's
+// email. It is not part of the caller's transaction: every change takes
+// effect at once and is not rolled back with a failed request, whichever
+// implementation is used, so revocations stick even when the rest of the
+// request fails.
+type SessionStore interface {
+	Create(ctx context.Context, session domain.Session) domain.Session
+	Get(ctx context.Context, id string) (domain.Session, error)
+	// FindByEmail lists the sessions that can still be renewed, most
+	// recently used first.
+	FindByEmail(ctx context.Context, email string) []domain.Session
+	UpdateActivity(ctx context.Context, session domain.Session) error
+	Revoke(ctx context.Context, id string) error
+	// RevokeForUser only revokes the session when it belongs to email.
+	RevokeForUser(ctx context.Context, id string, email string) error
+	RevokeAllForUser(ctx context.Context, email string) error
+	Delete(ctx context.Context, id string) error
+	DeleteAllForUser(ctx context.Context, email string) error
+	DeleteExpired(ctx context.Context) error
+}
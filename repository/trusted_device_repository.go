@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"golang_jwt/model/domain"
+)
+
+type TrustedDeviceRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, trustedDevice domain.TrustedDevice) domain.TrustedDevice
+	FindByTokenHash(ctx context.Context, tx *sql.Tx, tokenHash string) (domain.TrustedDevice, error)
+	FindByUserId(ctx context.Context, tx *sql.Tx, userId int) []domain.TrustedDevice
+	UpdateLastUsed(ctx context.Context, tx *sql.Tx, id string) error
+	Delete(ctx context.Context, tx *sql.Tx, id string, userId int) error
+	DeleteByUserId(ctx context.Context, tx *sql.Tx, userId int) error
+}
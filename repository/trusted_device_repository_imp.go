@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"golang_jwt/helper"
+	"golang_jwt/model/domain"
+)
+
+type trustedDeviceRepositoryImpl struct {
+}
+
+func NewTrustedDeviceRepository() TrustedDeviceRepository {
+	return &trustedDeviceRepositoryImpl{}
+}
+
+const trustedDeviceColumns = "id, user_id, token_hash, label, ip_address, user_agent, created_at, last_used_at, expires_at"
+
+func scanTrustedDevice(row rowScanner) (domain.TrustedDevice, error) {
+	trustedDevice := domain.TrustedDevice{}
+	err := row.Scan(&trustedDevice.ID, &trustedDevice.UserID, &trustedDevice.TokenHash, &trustedDevice.Label, &trustedDevice.IPAddress, &trustedDevice.UserAgent, &trustedDevice.CreatedAt, &trustedDevice.LastUsedAt, &trustedDevice.ExpiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return trustedDevice, errors.New("trusted device not found")
+		}
+		return trustedDevice, err
+	}
+	return trustedDevice, nil
+}
+
+// Save also clears the user's expired devices.
+func (repository *trustedDeviceRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, trustedDevice domain.TrustedDevice) domain.TrustedDevice {
+	_, err := tx.ExecContext(ctx, "DELETE FROM trusted_devices WHERE user_id = $1 AND expires_at < NOW()", trustedDevice.UserID)
+	helper.ErrorConditionCheck(err)
+
+	SQL := "INSERT INTO trusted_devices (id, user_id, token_hash, label, ip_address, user_agent, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING created_at, last_used_at"
+	err = tx.QueryRowContext(ctx, SQL, trustedDevice.ID, trustedDevice.UserID, trustedDevice.TokenHash, trustedDevice.Label, trustedDevice.IPAddress, trustedDevice.UserAgent, trustedDevice.ExpiresAt).Scan(&trustedDevice.CreatedAt, &trustedDevice.LastUsedAt)
+	helper.ErrorConditionCheck(err)
+	return trustedDevice
+}
+
+func (repository *trustedDeviceRepositoryImpl) FindByTokenHash(ctx context.Context, tx *sql.Tx, tokenHash string) (domain.TrustedDevice, error) {
+	SQL := "SELECT " + trustedDeviceColumns + " FROM trusted_devices WHERE token_hash = $1"
+	row := tx.QueryRowContext(ctx, SQL, tokenHash)
+	return scanTrustedDevice(row)
+}
+
+func (repository *trustedDeviceRepositoryImpl) FindByUserId(ctx context.Context, tx *sql.Tx, userId int) []domain.TrustedDevice {
+	SQL := "SELECT " + trustedDeviceColumns + " FROM trusted_devices WHERE user_id = $1 AND expires_at > NOW() ORDER BY last_used_at DESC"
+	rows, err := tx.QueryContext(ctx, SQL, userId)
+	helper.ErrorConditionCheck(err)
+	defer rows.Close()
+
+	var trustedDevices []domain.TrustedDevice
+	for rows.Next() {
+		trustedDevice, err := scanTrustedDevice(rows)
+		helper.ErrorConditionCheck(err)
+		trustedDevices = append(trustedDevices, trustedDevice)
+	}
+	return trustedDevices
+}
+
+func (repository *trustedDeviceRepositoryImpl) UpdateLastUsed(ctx context.Context, tx *sql.Tx, id string) error {
+	SQL := "UPDATE trusted_devices SET last_used_at = NOW() WHERE id = $1"
+	_, err := tx.ExecContext(ctx, SQL, id)
+	helper.ErrorConditionCheck(err)
+	return nil
+}
+
+func (repository *trustedDeviceRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, id string, userId int) error {
+	SQL := "DELETE FROM trusted_devices WHERE id = $1 AND user_id = $2"
+	result, err := tx.ExecContext(ctx, SQL, id, userId)
+	helper.ErrorConditionCheck(err)
+
+	affected, err := result.RowsAffected()
+	helper.ErrorConditionCheck(err)
+	if affected == 0 {
+		return errors.New("trusted device not found")
+	}
+	return nil
+}
+
+func (repository *trustedDeviceRepositoryImpl) DeleteByUserId(ctx context.Context, tx *sql.Tx, userId int) error {
+	SQL := "DELETE FROM trusted_devices WHERE user_id = $1"
+	_, err := tx.ExecContext(ctx, SQL, userId)
+	helper.ErrorConditionCheck(err)
+	return nil
+}
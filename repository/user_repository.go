@@ -3,20 +3,30 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"golang_jwt/model/domain"
 )
 
+// ErrEmailTaken is returned by Register when the email address, compared
+// case-insensitively, already belongs to an account.
+var ErrEmailTaken = errors.New("email is already registered")
+
 type UserRepository interface {
-	Register(ctx context.Context, tx *sql.Tx, user domain.User) domain.User
+	Register(ctx context.Context, tx *sql.Tx, user domain.User) (domain.User, error)
 	FindById(ctx context.Context, tx *sql.Tx, userId int) (domain.User, error)
 	FindAll(ctx context.Context, tx *sql.Tx) []domain.User
 	FindByEmail(ctx context.Context, tx *sql.Tx, email string) (domain.User, error)
-	CreateSession(ctx context.Context, tx *sql.Tx, session domain.Session) domain.Session
-	GetSession(ctx context.Context, tx *sql.Tx, id string) (domain.Session, error)
-	RevokeSession(ctx context.Context, tx *sql.Tx, id string) error
-	DeleteSession(ctx context.Context, tx *sql.Tx, id string) error
-
-	DeleteExpiredSessions(ctx context.Context, tx *sql.Tx) error
+	FindByUsername(ctx context.Context, tx *sql.Tx, username string) (domain.User, error)
+	UpdatePassword(ctx context.Context, tx *sql.Tx, userId int, password string) error
+	SaveTotpSecret(ctx context.Context, tx *sql.Tx, userId int, secret string) error
+	EnableTotp(ctx context.Context, tx *sql.Tx, userId int) error
+	DisableTotp(ctx context.Context, tx *sql.Tx, userId int) error
+	UpdateTotpLastStep(ctx context.Context, tx *sql.Tx, userId int, step int64) error
+	IncrementTokenVersion(ctx context.Context, tx *sql.Tx, userId int) int
+	// FindTokenVersion takes the pool rather than a transaction, as it is a
+	// single read done for every authenticated request.
+	FindTokenVersion(ctx context.Context, db *sql.DB, userId int) (int, error)
+	Delete(ctx context.Context, tx *sql.Tx, user domain.User) error
 }
 
 
@@ -15,39 +15,51 @@ func NewUserRepository() UserRepository {
 	return &userRepositoryImpl{}
 }
 
-func (repository *userRepositoryImpl) Register(ctx context.Context, tx *sql.Tx, user domain.User) domain.User {
-	SQL := "INSERT INTO users (username, email, password) VALUES ($1, $2, $3) RETURNING id"
-	var id int
-	err := tx.QueryRowContext(ctx, SQL, user.Username, user.Email, user.Password).Scan(&id) 
-	helper.ErrorConditionCheck(err)
-	user.ID = id
-	return user 
+func (repository *userRepositoryImpl) Register(ctx context.Context, tx *sql.Tx, user domain.User) (domain.User, error) {
+	SQL := "INSERT INTO users (username, email, password) VALUES ($1, $2, $3) ON CONFLICT (lower(email)) DO NOTHING RETURNING id, role"
+	err := tx.QueryRowContext(ctx, SQL, user.Username, user.Email, user.Password).Scan(&user.ID, &user.Role) 
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return user, ErrEmailTaken
+		}
+		return user, err
+	}
+	return user, nil
 }
 
-func (repository *userRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx, userId int) (domain.User, error) {
-	SQL := "SELECT id, username, email FROM users WHERE id = $1"
-	rows, err := tx.QueryContext(ctx, SQL, userId)
-	helper.ErrorConditionCheck(err)
-	defer rows.Close()
+const userColumns = "id, username, email, password, role, COALESCE(totp_secret, ''), totp_enabled, totp_last_step, token_version"
 
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row rowScanner) (domain.User, error) {
 	user := domain.User{}
-	if rows.Next() {
-		err := rows.Scan(&user.ID, &user.Username, &user.Email)
-		helper.ErrorConditionCheck(err)
-		return user, nil
-	} else {
-		return user, errors.New("user not found")
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role, &user.TotpSecret, &user.TotpEnabled, &user.TotpLastStep, &user.TokenVersion)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return user, errors.New("user not found")
+		}
+		return user, err
 	}
+	return user, nil
+}
+
+func (repository *userRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx, userId int) (domain.User, error) {
+	SQL := "SELECT " + userColumns + " FROM users WHERE id = $1"
+	row := tx.QueryRowContext(ctx, SQL, userId)
+	return scanUser(row)
 }
 
 func (repository *userRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx) []domain.User {
-	SQL := "SELECT id, username, email FROM users"
+	SQL := "SELECT " + userColumns + " FROM users"
 	rows, err := tx.QueryContext(ctx, SQL)
 	helper.ErrorConditionCheck(err)
+	defer rows.Close()
+
 	var users []domain.User
 	for rows.Next() {
-		var user domain.User
-		err := rows.Scan(&user.ID, &user.Username, &user.Email)
+		user, err := scanUser(rows)
 		helper.ErrorConditionCheck(err)
 		users = append(users, user)
 	}
@@ -55,56 +67,82 @@ func (repository *userRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx) [
 }
 
 func (repository *userRepositoryImpl) FindByEmail(ctx context.Context, tx *sql.Tx, email string) (domain.User, error) {
-	SQL := "SELECT id, username, email, password FROM users WHERE email = $1"
+	SQL := "SELECT " + userColumns + " FROM users WHERE lower(email) = lower($1)"
 	row := tx.QueryRowContext(ctx, SQL, email)
-	
-	user := domain.User{}
-	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return user, errors.New("user not found")
-		}
-		return user, err
-	}
-	return user, nil
+	return scanUser(row)
+}
+
+func (repository *userRepositoryImpl) FindByUsername(ctx context.Context, tx *sql.Tx, username string) (domain.User, error) {
+	SQL := "SELECT " + userColumns + " FROM users WHERE lower(username) = lower($1)"
+	row := tx.QueryRowContext(ctx, SQL, username)
+	return scanUser(row)
 }
 
-func (repository *userRepositoryImpl) CreateSession(ctx context.Context, tx *sql.Tx, session domain.Session) domain.Session {
-	SQL := "INSERT INTO sessions (id, user_email, refresh_token, is_revoked, expires_at) VALUES ($1, $2, $3, $4, $5)"
-	_, err := tx.ExecContext(ctx, SQL, session.ID, session.User_Email, session.Refresh_Token, session.Is_Revoked, session.Expires_At)
+func (repository *userRepositoryImpl) UpdatePassword(ctx context.Context, tx *sql.Tx, userId int, password string) error {
+	SQL := "UPDATE users SET password = $1 WHERE id = $2"
+	_, err := tx.ExecContext(ctx, SQL, password, userId)
 	helper.ErrorConditionCheck(err)
-	return session
+	return nil
 }
 
-func (repository *userRepositoryImpl) GetSession(ctx context.Context, tx *sql.Tx, id string) (domain.Session, error) {
-	SQL := "SELECT * FROM sessions WHERE id = $1"
-	row := tx.QueryRowContext(ctx, SQL, id)
-	
-	session := domain.Session{}
-	err := row.Scan(&session.ID, &session.User_Email, &session.Refresh_Token, &session.Is_Revoked, &session.Created_At, &session.Expires_At)
+func (repository *userRepositoryImpl) SaveTotpSecret(ctx context.Context, tx *sql.Tx, userId int, secret string) error {
+	SQL := "UPDATE users SET totp_secret = $1, totp_enabled = false, totp_last_step = 0 WHERE id = $2"
+	_, err := tx.ExecContext(ctx, SQL, secret, userId)
 	helper.ErrorConditionCheck(err)
-	return session, nil
+	return nil
 }
 
-func (repository *userRepositoryImpl) RevokeSession(ctx context.Context, tx *sql.Tx, id string) error {
-	SQL := "UPDATE sessions SET is_revoked = true WHERE id = $1"
-	_, err := tx.ExecContext(ctx, SQL, id)
+func (repository *userRepositoryImpl) EnableTotp(ctx context.Context, tx *sql.Tx, userId int) error {
+	SQL := "UPDATE users SET totp_enabled = true WHERE id = $1"
+	_, err := tx.ExecContext(ctx, SQL, userId)
 	helper.ErrorConditionCheck(err)
 	return nil
 }
 
-func (repository *userRepositoryImpl) DeleteSession(ctx context.Context, tx *sql.Tx, id string) error {
-	SQL := "DELETE FROM sessions WHERE id = $1"
-	_, err := tx.ExecContext(ctx, SQL, id)
+func (repository *userRepositoryImpl) DisableTotp(ctx context.Context, tx *sql.Tx, userId int) error {
+	SQL := "UPDATE users SET totp_secret = NULL, totp_enabled = false, totp_last_step = 0 WHERE id = $1"
+	_, err := tx.ExecContext(ctx, SQL, userId)
 	helper.ErrorConditionCheck(err)
 	return nil
 }
 
-func (repository *userRepositoryImpl) DeleteExpiredSessions(ctx context.Context, tx *sql.Tx) error {
-	SQL := "DELETE FROM sessions WHERE expires_at < NOW()"
-	_, err := tx.ExecContext(ctx, SQL)
+func (repository *userRepositoryImpl) UpdateTotpLastStep(ctx context.Context, tx *sql.Tx, userId int, step int64) error {
+	SQL := "UPDATE users SET totp_last_step = $1 WHERE id = $2"
+	_, err := tx.ExecContext(ctx, SQL, step, userId)
 	helper.ErrorConditionCheck(err)
 	return nil
 }
 
+// IncrementTokenVersion invalidates every token issued with the previous
+// version and returns the new one.
+func (repository *userRepositoryImpl) IncrementTokenVersion(ctx context.Context, tx *sql.Tx, userId int) int {
+	SQL := "UPDATE users SET token_version = token_version + 1 WHERE id = $1 RETURNING token_version"
+
+	var tokenVersion int
+	err := tx.QueryRowContext(ctx, SQL, userId).Scan(&tokenVersion)
+	helper.ErrorConditionCheck(err)
+	return tokenVersion
+}
+
+func (repository *userRepositoryImpl) FindTokenVersion(ctx context.Context, db *sql.DB, userId int) (int, error) {
+	SQL := "SELECT token_version FROM users WHERE id = $1"
+
+	var tokenVersion int
+	err := db.QueryRowContext(ctx, SQL, userId).Scan(&tokenVersion)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, errors.New("user not found")
+		}
+		return 0, err
+	}
+	return tokenVersion, nil
+}
 
+// Delete expects the user's sessions to be removed from the session store
+// first, because they reference the user by email; all other user data is
+// removed by ON DELETE CASCADE.
+func (repository *userRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, user domain.User) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM users WHERE id = $1", user.ID)
+	helper.ErrorConditionCheck(err)
+	return nil
+}
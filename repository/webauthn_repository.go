@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"golang_jwt/model/domain"
+)
+
+type WebauthnRepository interface {
+	SaveCredential(ctx context.Context, tx *sql.Tx, credential domain.WebauthnCredential) domain.WebauthnCredential
+	FindCredentialById(ctx context.Context, tx *sql.Tx, id string) (domain.WebauthnCredential, error)
+	FindCredentialsByUserId(ctx context.Context, tx *sql.Tx, userId int) []domain.WebauthnCredential
+	UpdateCredentialUsage(ctx context.Context, tx *sql.Tx, id string, signCount int64) error
+	DeleteCredential(ctx context.Context, tx *sql.Tx, id string, userId int) error
+	SaveChallenge(ctx context.Context, tx *sql.Tx, challenge domain.WebauthnChallenge) domain.WebauthnChallenge
+	TakeChallenge(ctx context.Context, tx *sql.Tx, id string, ceremony string) (domain.WebauthnChallenge, error)
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"golang_jwt/helper"
+	"golang_jwt/model/domain"
+)
+
+type webauthnRepositoryImpl struct {
+}
+
+func NewWebauthnRepository() WebauthnRepository {
+	return &webauthnRepositoryImpl{}
+}
+
+const webauthnCredentialColumns = "id, user_id, name, public_key, sign_count, aaguid, transports, created_at, last_used_at"
+
+func scanWebauthnCredential(row rowScanner) (domain.WebauthnCredential, error) {
+	credential := domain.WebauthnCredential{}
+	err := row.Scan(&credential.ID, &credential.UserID, &credential.Name, &credential.PublicKey, &credential.SignCount, &credential.AAGUID, &credential.Transports, &credential.CreatedAt, &credential.LastUsedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return credential, errors.New("credential not found")
+		}
+		return credential, err
+	}
+	return credential, nil
+}
+
+func (repository *webauthnRepositoryImpl) SaveCredential(ctx context.Context, tx *sql.Tx, credential domain.WebauthnCredential) domain.WebauthnCredential {
+	SQL := "INSERT INTO webauthn_credentials (id, user_id, name, public_key, sign_count, aaguid, transports) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING created_at"
+	err := tx.QueryRowContext(ctx, SQL, credential.ID, credential.UserID, credential.Name, credential.PublicKey, credential.SignCount, credential.AAGUID, credential.Transports).Scan(&credential.CreatedAt)
+	helper.ErrorConditionCheck(err)
+	return credential
+}
+
+func (repository *webauthnRepositoryImpl) FindCredentialById(ctx context.Context, tx *sql.Tx, id string) (domain.WebauthnCredential, error) {
+	SQL := "SELECT " + webauthnCredentialColumns + " FROM webauthn_credentials WHERE id = $1 FOR UPDATE"
+	row := tx.QueryRowContext(ctx, SQL, id)
+	return scanWebauthnCredential(row)
+}
+
+func (repository *webauthnRepositoryImpl) FindCredentialsByUserId(ctx context.Context, tx *sql.Tx, userId int) []domain.WebauthnCredential {
+	SQL := "SELECT " + webauthnCredentialColumns + " FROM webauthn_credentials WHERE user_id = $1 ORDER BY created_at"
+	rows, err := tx.QueryContext(ctx, SQL, userId)
+	helper.ErrorConditionCheck(err)
+	defer rows.Close()
+
+	var credentials []domain.WebauthnCredential
+	for rows.Next() {
+		credential, err := scanWebauthnCredential(rows)
+		helper.ErrorConditionCheck(err)
+		credentials = append(credentials, credential)
+	}
+	return credentials
+}
+
+func (repository *webauthnRepositoryImpl) UpdateCredentialUsage(ctx context.Context, tx *sql.Tx, id string, signCount int64) error {
+	SQL := "UPDATE webauthn_credentials SET sign_count = $1, last_used_at = NOW() WHERE id = $2"
+	_, err := tx.ExecContext(ctx, SQL, signCount, id)
+	helper.ErrorConditionCheck(err)
+	return nil
+}
+
+func (repository *webauthnRepositoryImpl) DeleteCredential(ctx context.Context, tx *sql.Tx, id string, userId int) error {
+	SQL := "DELETE FROM webauthn_credentials WHERE id = $1 AND user_id = $2"
+	result, err := tx.ExecContext(ctx, SQL, id, userId)
+	helper.ErrorConditionCheck(err)
+
+	affected, err := result.RowsAffected()
+	helper.ErrorConditionCheck(err)
+	if affected == 0 {
+		return errors.New("credential not found")
+	}
+	return nil
+}
+
+// SaveChallenge also clears expired challenges, which are never taken.
+func (repository *webauthnRepositoryImpl) SaveChallenge(ctx context.Context, tx *sql.Tx, challenge domain.WebauthnChallenge) domain.WebauthnChallenge {
+	_, err := tx.ExecContext(ctx, "DELETE FROM webauthn_challenges WHERE expires_at < NOW()")
+	helper.ErrorConditionCheck(err)
+
+	SQL := "INSERT INTO webauthn_challenges (id, user_id, challenge, ceremony, expires_at) VALUES ($1, $2, $3, $4, $5)"
+	_, err = tx.ExecContext(ctx, SQL, challenge.ID, challenge.UserID, challenge.Challenge, challenge.Ceremony, challenge.ExpiresAt)
+	helper.ErrorConditionCheck(err)
+	return challenge
+}
+
+// TakeChallenge deletes the challenge as it reads it, so each one can only
+// complete a single ceremony.
+func (repository *webauthnRepositoryImpl) TakeChallenge(ctx context.Context, tx *sql.Tx, id string, ceremony string) (domain.WebauthnChallenge, error) {
+	SQL := "DELETE FROM webauthn_challenges WHERE id = $1 AND ceremony = $2 AND expires_at > NOW() RETURNING id, user_id, challenge, ceremony, expires_at"
+	row := tx.QueryRowContext(ctx, SQL, id, ceremony)
+
+	challenge := domain.WebauthnChallenge{}
+	err := row.Scan(&challenge.ID, &challenge.UserID, &challenge.Challenge, &challenge.Ceremony, &challenge.ExpiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return challenge, errors.New("challenge not found")
+		}
+		return challenge, err
+	}
+	return challenge, nil
+}
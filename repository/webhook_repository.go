@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"golang_jwt/model/domain"
+	"time"
+)
+
+type WebhookRepository interface {
+	SaveEndpoint(ctx context.Context, tx *sql.Tx, endpoint domain.WebhookEndpoint) domain.WebhookEndpoint
+	FindEndpointById(ctx context.Context, tx *sql.Tx, id int64) (domain.WebhookEndpoint, error)
+	FindEndpoints(ctx context.Context, tx *sql.Tx) []domain.WebhookEndpoint
+	DeleteEndpoint(ctx context.Context, tx *sql.Tx, id int64) error
+	SaveOutboxEvent(ctx context.Context, tx *sql.Tx, event domain.WebhookOutboxEvent) domain.WebhookOutboxEvent
+	TakeOutboxEvents(ctx context.Context, tx *sql.Tx, limit int) []domain.WebhookOutboxEvent
+	SaveDelivery(ctx context.Context, tx *sql.Tx, delivery domain.WebhookDelivery) domain.WebhookDelivery
+	ClaimDueDelivery(ctx context.Context, tx *sql.Tx, now time.Time, leaseUntil time.Time) (domain.WebhookDelivery, bool)
+	UpdateDelivery(ctx context.Context, tx *sql.Tx, delivery domain.WebhookDelivery)
+	FindDeliveryById(ctx context.Context, tx *sql.Tx, id int64) (domain.WebhookDelivery, error)
+	FindDeliveries(ctx context.Context, tx *sql.Tx, filter domain.WebhookDeliveryFilter) []domain.WebhookDelivery
+}
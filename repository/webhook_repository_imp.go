@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"golang_jwt/helper"
+	"golang_jwt/model/domain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type webhookRepositoryImpl struct {
+}
+
+func NewWebhookRepository() WebhookRepository {
+	return &webhookRepositoryImpl{}
+}
+
+const webhookEndpointColumns = "id, url, secret, events, description, created_at"
+
+const webhookDeliveryColumns = "id, endpoint_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, updated_at"
+
+var errWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+
+// Subscribed event types are stored comma separated; they never contain
+// a comma themselves.
+func scanWebhookEndpoint(row rowScanner) (domain.WebhookEndpoint, error) {
+	endpoint := domain.WebhookEndpoint{}
+	var events string
+	err := row.Scan(&endpoint.ID, &endpoint.URL, &endpoint.Secret, &events, &endpoint.Description, &endpoint.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return endpoint, errors.New("webhook not found")
+		}
+		return endpoint, err
+	}
+	endpoint.Events = strings.Split(events, ",")
+	return endpoint, nil
+}
+
+func scanWebhookDelivery(row rowScanner) (domain.WebhookDelivery, error) {
+	delivery := domain.WebhookDelivery{}
+	err := row.Scan(&delivery.ID, &delivery.EndpointID, &delivery.EventID, &delivery.EventType, &delivery.Payload, &delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastStatusCode, &delivery.LastError, &delivery.CreatedAt, &delivery.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return delivery, errWebhookDeliveryNotFound
+		}
+		return delivery, err
+	}
+	return delivery, nil
+}
+
+func (repository *webhookRepositoryImpl) SaveEndpoint(ctx context.Context, tx *sql.Tx, endpoint domain.WebhookEndpoint) domain.WebhookEndpoint {
+	SQL := "INSERT INTO webhook_endpoints (url, secret, events, description) VALUES ($1, $2, $3, $4) RETURNING id, created_at"
+	err := tx.QueryRowContext(ctx, SQL, endpoint.URL, endpoint.Secret, strings.Join(endpoint.Events, ","), endpoint.Description).Scan(&endpoint.ID, &endpoint.CreatedAt)
+	helper.ErrorConditionCheck(err)
+	return endpoint
+}
+
+func (repository *webhookRepositoryImpl) FindEndpointById(ctx context.Context, tx *sql.Tx, id int64) (domain.WebhookEndpoint, error) {
+	SQL := "SELECT " + webhookEndpointColumns + " FROM webhook_endpoints WHERE id = $1"
+	row := tx.QueryRowContext(ctx, SQL, id)
+	return scanWebhookEndpoint(row)
+}
+
+func (repository *webhookRepositoryImpl) FindEndpoints(ctx context.Context, tx *sql.Tx) []domain.WebhookEndpoint {
+	SQL := "SELECT " + webhookEndpointColumns + " FROM webhook_endpoints ORDER BY id"
+	rows, err := tx.QueryContext(ctx, SQL)
+	helper.ErrorConditionCheck(err)
+	defer rows.Close()
+
+	var endpoints []domain.WebhookEndpoint
+	for rows.Next() {
+		endpoint, err := scanWebhookEndpoint(rows)
+		helper.ErrorConditionCheck(err)
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints
+}
+
+// DeleteEndpoint also removes the endpoint's deliveries through the
+// foreign key.
+func (repository *webhookRepositoryImpl) DeleteEndpoint(ctx context.Context, tx *sql.Tx, id int64) error {
+	SQL := "DELETE FROM webhook_endpoints WHERE id = $1"
+	result, err := tx.ExecContext(ctx, SQL, id)
+	helper.ErrorConditionCheck(err)
+
+	affected, err := result.RowsAffected()
+	helper.ErrorConditionCheck(err)
+	if affected == 0 {
+		return errors.New("webhook not found")
+	}
+	return nil
+}
+
+func (repository *webhookRepositoryImpl) SaveOutboxEvent(ctx context.Context, tx *sql.Tx, event domain.WebhookOutboxEvent) domain.WebhookOutboxEvent {
+	SQL := "INSERT INTO webhook_outbox (event_id, event_type, payload) VALUES ($1, $2, $3) RETURNING id, created_at"
+	err := tx.QueryRowContext(ctx, SQL, event.EventID, event.EventType, event.Payload).Scan(&event.ID, &event.CreatedAt)
+	helper.ErrorConditionCheck(err)
+	return event
+}
+
+// TakeOutboxEvents deletes the oldest events as it reads them. Rows locked
+// by another dispatcher are skipped, so instances never take the same event;
+// a rollback puts the events back.
+func (repository *webhookRepositoryImpl) TakeOutboxEvents(ctx context.Context, tx *sql.Tx, limit int) []domain.WebhookOutboxEvent {
+	SQL := "DELETE FROM webhook_outbox WHERE id IN (SELECT id FROM webhook_outbox ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED) RETURNING id, event_id, event_type, payload, created_at"
+	rows, err := tx.QueryContext(ctx, SQL, limit)
+	helper.ErrorConditionCheck(err)
+	defer rows.Close()
+
+	var events []domain.WebhookOutboxEvent
+	for rows.Next() {
+		event := domain.WebhookOutboxEvent{}
+		err := rows.Scan(&event.ID, &event.EventID, &event.EventType, &event.Payload, &event.CreatedAt)
+		helper.ErrorConditionCheck(err)
+		events = append(events, event)
+	}
+	helper.ErrorConditionCheck(rows.Err())
+	return events
+}
+
+func (repository *webhookRepositoryImpl) SaveDelivery(ctx context.Context, tx *sql.Tx, delivery domain.WebhookDelivery) domain.WebhookDelivery {
+	SQL := "INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload, status, next_attempt_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at, updated_at"
+	err := tx.QueryRowContext(ctx, SQL, delivery.EndpointID, delivery.EventID, delivery.EventType, delivery.Payload, delivery.Status, delivery.NextAttemptAt).Scan(&delivery.ID, &delivery.CreatedAt, &delivery.UpdatedAt)
+	helper.ErrorConditionCheck(err)
+	return delivery
+}
+
+// ClaimDueDelivery leases the oldest due delivery by moving its next attempt
+// to leaseUntil. Once the claiming transaction commits, other dispatchers
+// leave it alone until the lease runs out, e.g. after a crash mid-send.
+func (repository *webhookRepositoryImpl) ClaimDueDelivery(ctx context.Context, tx *sql.Tx, now time.Time, leaseUntil time.Time) (domain.WebhookDelivery, bool) {
+	SQL := "UPDATE webhook_deliveries SET next_attempt_at = $3 WHERE id = (SELECT id FROM webhook_deliveries WHERE status = $1 AND next_attempt_at <= $2 ORDER BY next_attempt_at LIMIT 1 FOR UPDATE SKIP LOCKED) RETURNING " + webhookDeliveryColumns
+	row := tx.QueryRowContext(ctx, SQL, domain.WebhookDeliveryPending, now, leaseUntil)
+
+	delivery, err := scanWebhookDelivery(row)
+	if err == errWebhookDeliveryNotFound {
+		return delivery, false
+	}
+	helper.ErrorConditionCheck(err)
+	return delivery, true
+}
+
+func (repository *webhookRepositoryImpl) UpdateDelivery(ctx context.Context, tx *sql.Tx, delivery domain.WebhookDelivery) {
+	SQL := "UPDATE webhook_deliveries SET status = $1, attempts = $2, next_attempt_at = $3, last_status_code = $4, last_error = $5, updated_at = NOW() WHERE id = $6"
+	_, err := tx.ExecContext(ctx, SQL, delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastStatusCode, delivery.LastError, delivery.ID)
+	helper.ErrorConditionCheck(err)
+}
+
+func (repository *webhookRepositoryImpl) FindDeliveryById(ctx context.Context, tx *sql.Tx, id int64) (domain.WebhookDelivery, error) {
+	SQL := "SELECT " + webhookDeliveryColumns + " FROM webhook_deliveries WHERE id = $1"
+	row := tx.QueryRowContext(ctx, SQL, id)
+	return scanWebhookDelivery(row)
+}
+
+// FindDeliveries returns the newest deliveries first; BeforeID pages
+// through older ones.
+func (repository *webhookRepositoryImpl) FindDeliveries(ctx context.Context, tx *sql.Tx, filter domain.WebhookDeliveryFilter) []domain.WebhookDelivery {
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.Replace(condition, "?", "$"+strconv.Itoa(len(args)), 1))
+	}
+
+	if filter.EndpointID != 0 {
+		where("endpoint_id = ?", filter.EndpointID)
+	}
+	if filter.Status != "" {
+		where("status = ?", filter.Status)
+	}
+	if filter.EventType != "" {
+		where("event_type = ?", filter.EventType)
+	}
+	if filter.BeforeID != 0 {
+		where("id < ?", filter.BeforeID)
+	}
+
+	SQL := "SELECT " + webhookDeliveryColumns + " FROM webhook_deliveries"
+	if len(conditions) > 0 {
+		SQL += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	SQL += " ORDER BY id DESC LIMIT $" + strconv.Itoa(len(args))
+
+	rows, err := tx.QueryContext(ctx, SQL, args...)
+	helper.ErrorConditionCheck(err)
+	defer rows.Close()
+
+	var deliveries []domain.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		helper.ErrorConditionCheck(err)
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries
+}
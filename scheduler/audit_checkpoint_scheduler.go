@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"context"
+	"golang_jwt/audit"
+	"log"
+	"time"
+)
+
+// AuditCheckpointScheduler periodically signs the end of the audit chain.
+type AuditCheckpointScheduler struct {
+	auditStore audit.Store
+	interval   time.Duration
+}
+
+func NewAuditCheckpointScheduler(auditStore audit.Store, interval time.Duration) *AuditCheckpointScheduler {
+	return &AuditCheckpointScheduler{
+		auditStore: auditStore,
+		interval:   interval,
+	}
+}
+
+// Start does nothing when the interval is zero, which disables checkpoints.
+func (s *AuditCheckpointScheduler) Start() {
+	if s.interval <= 0 {
+		return
+	}
+	log.Println("Starting audit checkpoint scheduler...")
+
+	ticker := time.NewTicker(s.interval)
+
+	go func() {
+		for range ticker.C {
+			s.runCheckpoint()
+		}
+	}()
+}
+
+func (s *AuditCheckpointScheduler) runCheckpoint() {
+	checkpoint, created, err := s.auditStore.CreateCheckpoint(context.Background())
+	if err != nil {
+		log.Printf("Error creating audit checkpoint: %v", err)
+		return
+	}
+	if created {
+		log.Printf("Audit checkpoint %d created at record %d", checkpoint.ID, checkpoint.EventID)
+	}
+}
@@ -2,23 +2,23 @@ package scheduler
 
 import (
 	"context"
-	"database/sql"
 	"log"
 	"time"
+	"golang_jwt/ratelimit"
 	"golang_jwt/repository"
 )
 
 type CleanupScheduler struct {
-	userRepo repository.UserRepository
-	db       *sql.DB
-	interval time.Duration
+	sessionStore   repository.SessionStore
+	rateLimitStore ratelimit.Store
+	interval       time.Duration
 }
 
-func NewCleanupScheduler(userRepo repository.UserRepository, db *sql.DB) *CleanupScheduler {
+func NewCleanupScheduler(sessionStore repository.SessionStore, rateLimitStore ratelimit.Store) *CleanupScheduler {
 	return &CleanupScheduler{
-		userRepo: userRepo,
-		db:       db,
-		interval: 24 * time.Hour,
+		sessionStore:   sessionStore,
+		rateLimitStore: rateLimitStore,
+		interval:       24 * time.Hour,
 	}
 }
 
@@ -46,38 +46,33 @@ func (s *CleanupScheduler) Start() {
 func (s *CleanupScheduler) runCleanup() {
 	ctx := context.Background()
 	
-	tx, err := s.db.Begin()
+	err := s.sessionStore.DeleteExpired(ctx)
 	if err != nil {
-		log.Printf("Error starting cleanup transaction: %v", err)
-		return
-	}
-	
-	err = s.userRepo.DeleteExpiredSessions(ctx, tx)
-	if err != nil {
-		tx.Rollback()
 		log.Printf("Error cleaning expired sessions: %v", err)
 	} else {
-		tx.Commit()
 		log.Println("Expired sessions cleanup completed")
 	}
+
+	err = s.rateLimitStore.DeleteExpired(ctx)
+	if err != nil {
+		log.Printf("Error cleaning expired rate limit counters: %v", err)
+	}
 }
 
 func (s *CleanupScheduler) ManualCleanup() error {
 	log.Println("Manual cleanup triggered")
 	
 	ctx := context.Background()
-	tx, err := s.db.Begin()
+	err := s.sessionStore.DeleteExpired(ctx)
 	if err != nil {
 		return err
 	}
-	
-	err = s.userRepo.DeleteExpiredSessions(ctx, tx)
+
+	err = s.rateLimitStore.DeleteExpired(ctx)
 	if err != nil {
-		tx.Rollback()
 		return err
 	}
-	
-	tx.Commit()
+
 	log.Println("Manual cleanup completed")
 	return nil
 }
\ No newline at end of file
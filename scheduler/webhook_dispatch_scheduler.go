@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"context"
+	"golang_jwt/service"
+	"log"
+	"time"
+)
+
+// WebhookDispatchScheduler periodically sends the webhooks waiting in the
+// outbox and retries failed deliveries that are due.
+type WebhookDispatchScheduler struct {
+	webhookService service.WebhookService
+	interval       time.Duration
+}
+
+func NewWebhookDispatchScheduler(webhookService service.WebhookService, interval time.Duration) *WebhookDispatchScheduler {
+	return &WebhookDispatchScheduler{
+		webhookService: webhookService,
+		interval:       interval,
+	}
+}
+
+// Start does nothing when the interval is zero, which disables delivery;
+// events then stay in the outbox.
+func (s *WebhookDispatchScheduler) Start() {
+	if s.interval <= 0 {
+		return
+	}
+	log.Println("Starting webhook dispatch scheduler...")
+
+	ticker := time.NewTicker(s.interval)
+
+	go func() {
+		for range ticker.C {
+			s.runDispatch()
+		}
+	}()
+}
+
+// runDispatch recovers from the panics the service raises on database
+// errors, so one failed run does not stop the scheduler.
+func (s *WebhookDispatchScheduler) runDispatch() {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Printf("Error dispatching webhooks: %v", err)
+		}
+	}()
+
+	s.webhookService.Dispatch(context.Background())
+}
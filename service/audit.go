@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"golang_jwt/audit"
+	"golang_jwt/helper"
+	"golang_jwt/webhook"
+	"log"
+	"time"
+)
+
+// recordAudit fills in the client details and the authenticated caller of
+// the current request and never lets a recording failure interrupt the
+// caller. Events are successes unless they say otherwise.
+func recordAudit(ctx context.Context, recorder audit.Recorder, event audit.Event) {
+	clientInfo := helper.ClientInfoFromContext(ctx)
+	event.IPAddress = clientInfo.IPAddress
+	event.UserAgent = clientInfo.UserAgent
+	event.OccurredAt = time.Now()
+
+	actor := audit.ActorFromContext(ctx)
+	if event.ActorID == 0 {
+		event.ActorID = actor.UserID
+	}
+	if event.SessionID == "" {
+		event.SessionID = actor.SessionID
+	}
+	if event.Outcome == "" {
+		event.Outcome = audit.OutcomeSuccess
+	}
+
+	err := recorder.Record(ctx, event)
+	if err != nil {
+		log.Printf("Error recording audit event %s: %v", event.Type, err)
+	}
+}
+
+// recordLoginFailure audits a failed login with the method that was tried
+// and publishes it as a login.failed webhook. userId is zero when the
+// attempt matched no account.
+func recordLoginFailure(ctx context.Context, recorder audit.Recorder, webhookService WebhookService, method string, userId int, reason string) {
+	recordAudit(ctx, recorder, audit.Event{
+		Type:    audit.EventLogin,
+		Outcome: audit.OutcomeFailure,
+		UserID:  userId,
+		Metadata: map[string]string{
+			"methods": method,
+			"reason":  reason,
+		},
+	})
+
+	data := map[string]interface{}{
+		"methods": method,
+		"reason":  reason,
+	}
+	if userId != 0 {
+		data["user_id"] = userId
+	}
+	webhookService.PublishSeparately(ctx, webhook.EventLoginFailed, data)
+}
@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+	"golang_jwt/model/web"
+)
+
+// AuditService lets admins search the security audit log and check that
+// it has not been edited.
+type AuditService interface {
+	FindAll(ctx context.Context, request web.AuditEventQueryRequest) []web.AuditEventResponse
+	VerifyChain(ctx context.Context) web.AuditChainVerificationResponse
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"golang_jwt/audit"
+	"golang_jwt/helper"
+	"golang_jwt/model/web"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type AuditServiceImpl struct {
+	AuditStore audit.Store
+	Validate   *validator.Validate
+}
+
+func NewAuditService(auditStore audit.Store, Validate *validator.Validate) AuditService {
+	return &AuditServiceImpl{
+		AuditStore: auditStore,
+		Validate:   Validate,
+	}
+}
+
+const (
+	defaultAuditEventLimit = 50
+	maxAuditEventLimit     = 500
+)
+
+func (service *AuditServiceImpl) FindAll(ctx context.Context, request web.AuditEventQueryRequest) []web.AuditEventResponse {
+	err := service.Validate.Struct(request)
+	helper.ErrorConditionCheck(err)
+
+	// The timestamps were validated above, so parse errors cannot occur.
+	filter := audit.Filter{
+		Type:      audit.EventType(request.Type),
+		Outcome:   audit.Outcome(request.Outcome),
+		SessionID: request.SessionId,
+		IPAddress: request.IPAddress,
+		Limit:     defaultAuditEventLimit,
+	}
+	filter.ActorID = int(parseQueryNumber("actor_id", request.ActorId, 32))
+	filter.UserID = int(parseQueryNumber("user_id", request.UserId, 32))
+	filter.BeforeID = parseQueryNumber("before_id", request.BeforeId, 64)
+	filter.From, _ = time.Parse(time.RFC3339, request.From)
+	filter.To, _ = time.Parse(time.RFC3339, request.To)
+	if limit := int(parseQueryNumber("limit", request.Limit, 32)); limit > 0 {
+		filter.Limit = min(limit, maxAuditEventLimit)
+	}
+
+	events, err := service.AuditStore.Find(ctx, filter)
+	helper.ErrorConditionCheck(err)
+
+	return helper.ToAuditEventResponses(events)
+}
+
+func (service *AuditServiceImpl) VerifyChain(ctx context.Context) web.AuditChainVerificationResponse {
+	verification, err := service.AuditStore.VerifyChain(ctx)
+	helper.ErrorConditionCheck(err)
+
+	return helper.ToAuditChainVerificationResponse(verification)
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"golang_jwt/model/web"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Authentication describes how and when the user proved their identity. It
+// is carried into the amr, acr and auth_time claims of issued tokens.
+type Authentication struct {
+	Methods []string
+	Time    time.Time
+}
+
+func NewAuthentication(methods ...string) Authentication {
+	return Authentication{
+		Methods: methods,
+		Time:    time.Now(),
+	}
+}
+
+// authenticationFromClaims restores the first factor recorded in an MFA
+// challenge token.
+func authenticationFromClaims(claims *web.UserClaims) Authentication {
+	authentication := Authentication{Methods: claims.AuthMethods, Time: time.Now()}
+	if claims.AuthTime != nil {
+		authentication.Time = claims.AuthTime.Time
+	}
+	return authentication
+}
+
+// WithSecondFactor adds a second factor and marks the login as multi-factor.
+func (authentication Authentication) WithSecondFactor(method string) Authentication {
+	methods := append([]string{}, authentication.Methods...)
+	authentication.Methods = append(methods, method, web.AmrMultiFactor)
+	authentication.Time = time.Now()
+	return authentication
+}
+
+func (authentication Authentication) Acr() string {
+	for _, method := range authentication.Methods {
+		if method == web.AmrMultiFactor {
+			return web.AcrMultiFactor
+		}
+	}
+	return web.AcrSingleFactor
+}
+
+func (authentication Authentication) applyTo(claims *web.UserClaims) {
+	claims.AuthMethods = authentication.Methods
+	claims.Acr = authentication.Acr()
+	claims.AuthTime = jwt.NewNumericDate(authentication.Time)
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golang_jwt/exception"
+	"golang_jwt/hasher"
+	"golang_jwt/helper"
+	"time"
+)
+
+// hasherErrorCheck answers 503 when the hashing pool is full, so a burst of
+// logins is shed right away instead of timing out in the queue. A request
+// that gave up while waiting for a worker gets the same answer.
+func hasherErrorCheck(err error) {
+	if errors.Is(err, hasher.ErrPoolSaturated) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		panic(exception.NewServiceUnavailableError("server is busy, please try again later", time.Second))
+	}
+	helper.ErrorConditionCheck(err)
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"golang_jwt/model/domain"
+	"golang_jwt/model/web"
+	"time"
+)
+
+type LoginLockoutService interface {
+	CheckAllowed(ctx context.Context, accountKey string)
+	RecordFailure(ctx context.Context, user *domain.User, accountKey string)
+	RecordSuccess(ctx context.Context, accountKey string)
+	UnlockAccount(ctx context.Context, userId int)
+	RedeemUnlockToken(ctx context.Context, request web.UnlockAccountRequest)
+}
+
+// LockoutConfig controls progressive delays and temporary lockouts. Once the
+// failures for a key reach its backoff threshold, each further attempt must
+// wait BaseDelay doubled per extra failure (capped at MaxDelay); reaching the
+// lockout threshold blocks the key for LockoutDuration.
+type LockoutConfig struct {
+	FailureWindow           time.Duration
+	AccountBackoffThreshold int
+	AccountLockoutThreshold int
+	IPBackoffThreshold      int
+	IPLockoutThreshold      int
+	BaseDelay               time.Duration
+	MaxDelay                time.Duration
+	LockoutDuration         time.Duration
+	UnlockTokenTTL          time.Duration
+	BaseURL                 string
+}
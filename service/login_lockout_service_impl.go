@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"golang_jwt/audit"
+	"golang_jwt/exception"
+	"golang_jwt/helper"
+	"golang_jwt/mailer"
+	"golang_jwt/model/domain"
+	"golang_jwt/model/web"
+	"golang_jwt/repository"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type LoginLockoutServiceImpl struct {
+	LoginAttemptRepository  repository.LoginAttemptRepository
+	AccountUnlockRepository repository.AccountUnlockRepository
+	UserRepository          repository.UserRepository
+	DB                      *sql.DB
+	Validate                *validator.Validate
+	Mailer                  mailer.Mailer
+	AuditRecorder           audit.Recorder
+	Config                  LockoutConfig
+}
+
+func NewLoginLockoutService(loginAttemptRepository repository.LoginAttemptRepository, accountUnlockRepository repository.AccountUnlockRepository, userRepository repository.UserRepository, DB *sql.DB, Validate *validator.Validate, mailer mailer.Mailer, auditRecorder audit.Recorder, config LockoutConfig) LoginLockoutService {
+	return &LoginLockoutServiceImpl{
+		LoginAttemptRepository:  loginAttemptRepository,
+		AccountUnlockRepository: accountUnlockRepository,
+		UserRepository:          userRepository,
+		DB:                      DB,
+		Validate:                Validate,
+		Mailer:                  mailer,
+		AuditRecorder:           auditRecorder,
+		Config:                  config,
+	}
+}
+
+// AccountKeyForUser identifies a known account regardless of which
+// identifier was used to log in.
+func AccountKeyForUser(userId int) string {
+	return "user:" + strconv.Itoa(userId)
+}
+
+// AccountKeyForLogin is used when the identifier does not match an account,
+// so unknown accounts are throttled exactly like real ones.
+func AccountKeyForLogin(identifier string) string {
+	return "login:" + strings.ToLower(strings.TrimSpace(identifier))
+}
+
+func (service *LoginLockoutServiceImpl) CheckAllowed(ctx context.Context, accountKey string) {
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	ipAddress := helper.ClientInfoFromContext(ctx).IPAddress
+	if ipAddress != "" {
+		loginAttempt, err := service.LoginAttemptRepository.Find(ctx, tx, domain.LoginAttemptKeyIP, ipAddress)
+		if err == nil {
+			service.checkAttempt(loginAttempt, service.Config.IPBackoffThreshold)
+		}
+	}
+
+	loginAttempt, err := service.LoginAttemptRepository.Find(ctx, tx, domain.LoginAttemptKeyAccount, accountKey)
+	if err == nil {
+		service.checkAttempt(loginAttempt, service.Config.AccountBackoffThreshold)
+	}
+}
+
+// RecordFailure commits in its own transaction so the counters survive the
+// rollback of the failed login. The unlock email is sent in the background,
+// so the response time does not reveal whether the account exists.
+func (service *LoginLockoutServiceImpl) RecordFailure(ctx context.Context, user *domain.User, accountKey string) {
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	ipAddress := helper.ClientInfoFromContext(ctx).IPAddress
+	if ipAddress != "" {
+		loginAttempt := service.LoginAttemptRepository.RecordFailure(ctx, tx, domain.LoginAttemptKeyIP, ipAddress, service.Config.FailureWindow)
+		if loginAttempt.Failures >= service.Config.IPLockoutThreshold && !isLocked(loginAttempt) {
+			service.LoginAttemptRepository.Lock(ctx, tx, domain.LoginAttemptKeyIP, ipAddress, time.Now().Add(service.Config.LockoutDuration))
+		}
+	}
+
+	loginAttempt := service.LoginAttemptRepository.RecordFailure(ctx, tx, domain.LoginAttemptKeyAccount, accountKey, service.Config.FailureWindow)
+	if loginAttempt.Failures >= service.Config.AccountLockoutThreshold && !isLocked(loginAttempt) {
+		service.LoginAttemptRepository.Lock(ctx, tx, domain.LoginAttemptKeyAccount, accountKey, time.Now().Add(service.Config.LockoutDuration))
+		log.Printf("Account %s locked after %d failed login attempts", accountKey, loginAttempt.Failures)
+
+		if user != nil {
+			service.sendUnlockEmail(ctx, tx, *user)
+		}
+	}
+}
+
+func (service *LoginLockoutServiceImpl) RecordSuccess(ctx context.Context, accountKey string) {
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	service.LoginAttemptRepository.Delete(ctx, tx, domain.LoginAttemptKeyAccount, accountKey)
+}
+
+func (service *LoginLockoutServiceImpl) UnlockAccount(ctx context.Context, userId int) {
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	user, err := service.UserRepository.FindById(ctx, tx, userId)
+	if err != nil {
+		panic(exception.NewNotFoundError(err.Error()))
+	}
+
+	service.LoginAttemptRepository.Delete(ctx, tx, domain.LoginAttemptKeyAccount, AccountKeyForUser(user.ID))
+	service.recordUnlock(ctx, user.ID, "admin")
+}
+
+func (service *LoginLockoutServiceImpl) RedeemUnlockToken(ctx context.Context, request web.UnlockAccountRequest) {
+	err := service.Validate.Struct(request)
+	helper.ErrorConditionCheck(err)
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	accountUnlock, err := service.AccountUnlockRepository.FindByTokenHash(ctx, tx, helper.HashToken(request.Token))
+	if err != nil || accountUnlock.UsedAt != nil || time.Now().After(accountUnlock.ExpiresAt) {
+		panic(exception.NewNotFoundError("unlock token is invalid or expired"))
+	}
+
+	service.AccountUnlockRepository.MarkUsed(ctx, tx, accountUnlock.ID)
+	service.LoginAttemptRepository.Delete(ctx, tx, domain.LoginAttemptKeyAccount, AccountKeyForUser(accountUnlock.UserID))
+	service.recordUnlock(ctx, accountUnlock.UserID, "unlock_token")
+}
+
+func (service *LoginLockoutServiceImpl) recordUnlock(ctx context.Context, userId int, method string) {
+	recordAudit(ctx, service.AuditRecorder, audit.Event{
+		Type:   audit.EventAccountUnlocked,
+		UserID: userId,
+		Metadata: map[string]string{
+			"method": method,
+		},
+	})
+}
+
+func (service *LoginLockoutServiceImpl) checkAttempt(loginAttempt domain.LoginAttempt, backoffThreshold int) {
+	now := time.Now()
+
+	if isLocked(loginAttempt) {
+		retryAfter := loginAttempt.LockedUntil.Sub(now)
+		if loginAttempt.KeyType == domain.LoginAttemptKeyAccount {
+			panic(exception.NewLockedError("account is temporarily locked", retryAfter))
+		}
+		panic(exception.NewTooManyRequestsError("too many failed login attempts", retryAfter))
+	}
+
+	if now.Sub(loginAttempt.LastFailureAt) > service.Config.FailureWindow {
+		return
+	}
+
+	nextAttemptAt := loginAttempt.LastFailureAt.Add(service.backoffDelay(loginAttempt.Failures, backoffThreshold))
+	if now.Before(nextAttemptAt) {
+		panic(exception.NewTooManyRequestsError("too many failed login attempts", nextAttemptAt.Sub(now)))
+	}
+}
+
+func (service *LoginLockoutServiceImpl) backoffDelay(failures int, threshold int) time.Duration {
+	if failures < threshold {
+		return 0
+	}
+
+	delay := service.Config.BaseDelay
+	for i := threshold; i < failures && delay < service.Config.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > service.Config.MaxDelay {
+		delay = service.Config.MaxDelay
+	}
+	return delay
+}
+
+func (service *LoginLockoutServiceImpl) sendUnlockEmail(ctx context.Context, tx *sql.Tx, user domain.User) {
+	token := helper.GenerateRandomToken(32)
+	service.AccountUnlockRepository.Save(ctx, tx, domain.AccountUnlock{
+		UserID:    user.ID,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(service.Config.UnlockTokenTTL),
+	})
+
+	sendMailAsync(ctx, service.Mailer, mailer.Message{
+		To:      user.Email,
+		Subject: "Your account has been locked",
+		Body: fmt.Sprintf("Your account was temporarily locked after too many failed sign-in attempts.\n\nIt will unlock automatically in %s. If it was you, you can unlock it now:\n%s/unlock-account?token=%s\n\nIf it wasn't you, consider changing your password.",
+			service.Config.LockoutDuration, service.Config.BaseURL, token),
+	}, "account unlock email")
+}
+
+func isLocked(loginAttempt domain.LoginAttempt) bool {
+	return loginAttempt.LockedUntil != nil && time.Now().Before(*loginAttempt.LockedUntil)
+}
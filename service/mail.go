@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+	"golang_jwt/mailer"
+	"log"
+)
+
+// sendMailAsync sends the message in the background, so requests that only
+// mail known addresses answer as fast for unknown ones and the mail server's
+// latency does not reveal which addresses have an account. The request
+// context is detached, as the response is usually written before the email
+// goes out.
+func sendMailAsync(ctx context.Context, sender mailer.Mailer, message mailer.Message, description string) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		err := sender.Send(ctx, message)
+		if err != nil {
+			log.Printf("Error sending %s: %v", description, err)
+		}
+	}()
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"golang_jwt/model/domain"
+	"golang_jwt/model/web"
+)
+
+type MfaService interface {
+	EnrollTotp(ctx context.Context, userId int) web.TotpEnrollmentResponse
+	ConfirmTotp(ctx context.Context, userId int, request web.TotpCodeRequest) web.RecoveryCodesResponse
+	DisableTotp(ctx context.Context, userId int, request web.TotpCodeRequest)
+	CompleteLogin(ctx context.Context, request web.MfaLoginRequest) web.UserLoginResponse
+	RegenerateRecoveryCodes(ctx context.Context, userId int, request web.TotpCodeRequest) web.RecoveryCodesResponse
+	RecoveryCodeStatus(ctx context.Context, userId int) web.RecoveryCodeStatusResponse
+	// VerifySecondFactor checks a TOTP code, or a recovery code when one is
+	// given, inside the caller's transaction. It returns the amr method that
+	// was checked, web.AmrOtp or web.AmrRecoveryCode.
+	VerifySecondFactor(ctx context.Context, tx *sql.Tx, user domain.User, code string, recoveryCode string) (string, bool)
+}
+
+type MfaConfig struct {
+	Issuer        string
+	EncryptionKey []byte
+	RecoveryCodes int
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"golang_jwt/audit"
+	"golang_jwt/exception"
+	"golang_jwt/helper"
+	"golang_jwt/model/domain"
+	"golang_jwt/model/web"
+	"golang_jwt/repository"
+	"golang_jwt/token"
+	"golang_jwt/totp"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type MfaServiceImpl struct {
+	UserRepository         repository.UserRepository
+	RecoveryCodeRepository repository.RecoveryCodeRepository
+	DB                     *sql.DB
+	Validate               *validator.Validate
+	UserToken              token.UserToken
+	LoginLockoutService    LoginLockoutService
+	SessionIssuer          SessionIssuer
+	TrustedDeviceService   TrustedDeviceService
+	AuditRecorder          audit.Recorder
+	WebhookService         WebhookService
+	Config                 MfaConfig
+}
+
+func NewMfaService(userRepository repository.UserRepository, recoveryCodeRepository repository.RecoveryCodeRepository, DB *sql.DB, Validate *validator.Validate, userToken token.UserToken, loginLockoutService LoginLockoutService, sessionIssuer SessionIssuer, trustedDeviceService TrustedDeviceService, auditRecorder audit.Recorder, webhookService WebhookService, config MfaConfig) MfaService {
+	return &MfaServiceImpl{
+		UserRepository:         userRepository,
+		RecoveryCodeRepository: recoveryCodeRepository,
+		DB:                     DB,
+		Validate:               Validate,
+		UserToken:              userToken,
+		LoginLockoutService:    loginLockoutService,
+		SessionIssuer:          sessionIssuer,
+		TrustedDeviceService:   trustedDeviceService,
+		AuditRecorder:          auditRecorder,
+		WebhookService:         webhookService,
+		Config:                 config,
+	}
+}
+
+// EnrollTotp stores a new pending secret. It only protects logins once the
+// user proves their authenticator works through ConfirmTotp.
+func (service *MfaServiceImpl) EnrollTotp(ctx context.Context, userId int) web.TotpEnrollmentResponse {
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	user := service.findUser(ctx, tx, userId)
+	if user.TotpEnabled {
+		panic(exception.NewValidationError([]web.FieldError{{
+			Field:   "totp",
+			Rule:    "already_enabled",
+			Message: "two-factor authentication is already enabled",
+		}}))
+	}
+
+	secret, err := totp.GenerateSecret()
+	helper.ErrorConditionCheck(err)
+
+	encryptedSecret, err := helper.EncryptSecret(service.Config.EncryptionKey, secret)
+	helper.ErrorConditionCheck(err)
+
+	service.UserRepository.SaveTotpSecret(ctx, tx, user.ID, encryptedSecret)
+
+	return web.TotpEnrollmentResponse{
+		Secret:     secret,
+		OtpauthURI: totp.URI(service.Config.Issuer, user.Email, secret),
+	}
+}
+
+// ConfirmTotp activates the pending secret and hands out a fresh set of
+// recovery codes, which is the only time they are shown.
+func (service *MfaServiceImpl) ConfirmTotp(ctx context.Context, userId int, request web.TotpCodeRequest) web.RecoveryCodesResponse {
+	err := service.Validate.Struct(request)
+	helper.ErrorConditionCheck(err)
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	user := service.findUser(ctx, tx, userId)
+	if user.TotpSecret == "" || user.TotpEnabled {
+		panic(exception.NewNotFoundError("no pending two-factor enrollment"))
+	}
+
+	if !service.verifyCode(ctx, tx, user, request.Code) {
+		panic(invalidCodeError())
+	}
+
+	service.UserRepository.EnableTotp(ctx, tx, user.ID)
+
+	return service.generateRecoveryCodes(ctx, tx, user.ID)
+}
+
+func (service *MfaServiceImpl) DisableTotp(ctx context.Context, userId int, request web.TotpCodeRequest) {
+	err := service.Validate.Struct(request)
+	helper.ErrorConditionCheck(err)
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	user := service.findUser(ctx, tx, userId)
+	if !user.TotpEnabled {
+		panic(exception.NewNotFoundError("two-factor authentication is not enabled"))
+	}
+
+	if !service.verifyCode(ctx, tx, user, request.Code) {
+		panic(invalidCodeError())
+	}
+
+	service.UserRepository.DisableTotp(ctx, tx, user.ID)
+	service.RecoveryCodeRepository.DeleteByUserId(ctx, tx, user.ID)
+	service.TrustedDeviceService.RevokeAll(ctx, tx, user.ID)
+}
+
+// CompleteLogin is the second step of an MFA login: it exchanges the
+// challenge token from Login plus a valid code for a session. With
+// TrustDevice the response also carries a device token for later logins.
+func (service *MfaServiceImpl) CompleteLogin(ctx context.Context, request web.MfaLoginRequest) web.UserLoginResponse {
+	err := service.Validate.Struct(request)
+	helper.ErrorConditionCheck(err)
+
+	mfaClaims := service.validateMfaToken(request.MfaToken)
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	user, err := service.UserRepository.FindById(ctx, tx, mfaClaims.ID)
+	if err != nil || !user.TotpEnabled || mfaClaims.TokenVersion != user.TokenVersion {
+		panic(exception.NewUnauthorizedError("invalid or expired mfa token"))
+	}
+
+	accountKey := AccountKeyForUser(user.ID)
+	service.LoginLockoutService.CheckAllowed(ctx, accountKey)
+
+	method, ok := service.VerifySecondFactor(ctx, tx, user, request.Code, request.RecoveryCode)
+	if !ok {
+		service.LoginLockoutService.RecordFailure(ctx, &user, accountKey)
+		recordLoginFailure(ctx, service.AuditRecorder, service.WebhookService, method, user.ID, "invalid_code")
+		panic(exception.NewUnauthorizedError("invalid credentials"))
+	}
+	service.LoginLockoutService.RecordSuccess(ctx, accountKey)
+
+	userLoginResponse := service.SessionIssuer.IssueSession(ctx, tx, user, authenticationFromClaims(mfaClaims).WithSecondFactor(method))
+	if request.TrustDevice {
+		deviceToken, expiresAt := service.TrustedDeviceService.Trust(ctx, tx, user.ID)
+		userLoginResponse.DeviceToken = deviceToken
+		userLoginResponse.DeviceTokenExpiresAt = &expiresAt
+	}
+	return userLoginResponse
+}
+
+// RegenerateRecoveryCodes asks for a current TOTP code so a stolen access
+// token alone cannot replace the user's codes.
+func (service *MfaServiceImpl) RegenerateRecoveryCodes(ctx context.Context, userId int, request web.TotpCodeRequest) web.RecoveryCodesResponse {
+	err := service.Validate.Struct(request)
+	helper.ErrorConditionCheck(err)
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	user := service.findUser(ctx, tx, userId)
+	if !user.TotpEnabled {
+		panic(exception.NewNotFoundError("two-factor authentication is not enabled"))
+	}
+
+	if !service.verifyCode(ctx, tx, user, request.Code) {
+		panic(invalidCodeError())
+	}
+
+	return service.generateRecoveryCodes(ctx, tx, user.ID)
+}
+
+func (service *MfaServiceImpl) RecoveryCodeStatus(ctx context.Context, userId int) web.RecoveryCodeStatusResponse {
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	user := service.findUser(ctx, tx, userId)
+	total, remaining := service.RecoveryCodeRepository.Count(ctx, tx, user.ID)
+
+	return web.RecoveryCodeStatusResponse{
+		Total:     total,
+		Remaining: remaining,
+	}
+}
+
+func (service *MfaServiceImpl) VerifySecondFactor(ctx context.Context, tx *sql.Tx, user domain.User, code string, recoveryCode string) (string, bool) {
+	if recoveryCode != "" {
+		return web.AmrRecoveryCode, service.redeemRecoveryCode(ctx, tx, user.ID, recoveryCode)
+	}
+	return web.AmrOtp, service.verifyCode(ctx, tx, user, code)
+}
+
+func (service *MfaServiceImpl) generateRecoveryCodes(ctx context.Context, tx *sql.Tx, userId int) web.RecoveryCodesResponse {
+	codes := make([]string, service.Config.RecoveryCodes)
+	codeHashes := make([]string, service.Config.RecoveryCodes)
+	for i := range codes {
+		codes[i] = generateRecoveryCode()
+		codeHashes[i] = helper.HashToken(normalizeRecoveryCode(codes[i]))
+	}
+
+	service.RecoveryCodeRepository.ReplaceAll(ctx, tx, userId, codeHashes)
+	recordAudit(ctx, service.AuditRecorder, audit.Event{
+		Type:   audit.EventRecoveryCodesGenerated,
+		UserID: userId,
+		Metadata: map[string]string{
+			"count": strconv.Itoa(len(codes)),
+		},
+	})
+
+	return web.RecoveryCodesResponse{Codes: codes}
+}
+
+func (service *MfaServiceImpl) redeemRecoveryCode(ctx context.Context, tx *sql.Tx, userId int, code string) bool {
+	err := service.RecoveryCodeRepository.Consume(ctx, tx, userId, helper.HashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		recordAudit(ctx, service.AuditRecorder, audit.Event{
+			Type:    audit.EventRecoveryCodeRejected,
+			Outcome: audit.OutcomeFailure,
+			UserID:  userId,
+		})
+		return false
+	}
+
+	_, remaining := service.RecoveryCodeRepository.Count(ctx, tx, userId)
+	recordAudit(ctx, service.AuditRecorder, audit.Event{
+		Type:   audit.EventRecoveryCodeUsed,
+		UserID: userId,
+		Metadata: map[string]string{
+			"remaining": strconv.Itoa(remaining),
+		},
+	})
+	return true
+}
+
+func (service *MfaServiceImpl) validateMfaToken(mfaToken string) *web.UserClaims {
+	claims := validateTokenSafely(service.UserToken, mfaToken)
+	if claims == nil || claims.TokenType != web.TokenTypeMfa {
+		panic(exception.NewUnauthorizedError("invalid or expired mfa token"))
+	}
+	return claims
+}
+
+// verifyCode accepts each time step at most once, so an observed code cannot
+// be replayed within its validity window.
+func (service *MfaServiceImpl) verifyCode(ctx context.Context, tx *sql.Tx, user domain.User, code string) bool {
+	secret, err := helper.DecryptSecret(service.Config.EncryptionKey, user.TotpSecret)
+	helper.ErrorConditionCheck(err)
+
+	step, ok := totp.Validate(secret, code, time.Now(), 1)
+	if !ok || step <= user.TotpLastStep {
+		return false
+	}
+
+	service.UserRepository.UpdateTotpLastStep(ctx, tx, user.ID, step)
+	return true
+}
+
+func (service *MfaServiceImpl) findUser(ctx context.Context, tx *sql.Tx, userId int) domain.User {
+	user, err := service.UserRepository.FindById(ctx, tx, userId)
+	if err != nil {
+		panic(exception.NewNotFoundError(err.Error()))
+	}
+	return user
+}
+
+func invalidCodeError() exception.ValidationError {
+	return exception.NewValidationError([]web.FieldError{{
+		Field:   "code",
+		Rule:    "invalid",
+		Message: "verification code is invalid",
+	}})
+}
+
+// recoveryCodeAlphabet leaves out characters that are easily confused when
+// a code is read from paper: 0/o, 1/l/i.
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// generateRecoveryCode returns a code such as "k7m2p-x9qrt" with about 49
+// bits of entropy.
+func generateRecoveryCode() string {
+	code := make([]byte, 0, 11)
+	for i := 0; i < 10; i++ {
+		if i == 5 {
+			code = append(code, '-')
+		}
+		index, err := rand.Int(rand.Reader, big.NewInt(int64(len(recoveryCodeAlphabet))))
+		helper.ErrorConditionCheck(err)
+		code = append(code, recoveryCodeAlphabet[index.Int64()])
+	}
+	return string(code)
+}
+
+// normalizeRecoveryCode makes input case and separator insensitive.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"golang_jwt/model/web"
+	"time"
+)
+
+type PasskeyService interface {
+	BeginRegistration(ctx context.Context, userId int) web.PasskeyRegistrationOptionsResponse
+	FinishRegistration(ctx context.Context, userId int, request web.PasskeyRegistrationRequest) web.PasskeyResponse
+	FindAll(ctx context.Context, userId int) []web.PasskeyResponse
+	Delete(ctx context.Context, userId int, credentialId string)
+	BeginLogin(ctx context.Context, request web.PasskeyLoginOptionsRequest) web.PasskeyLoginOptionsResponse
+	FinishLogin(ctx context.Context, request web.PasskeyLoginRequest) web.UserLoginResponse
+}
+
+type PasskeyConfig struct {
+	RPID                    string
+	RPName                  string
+	Origins                 []string
+	ChallengeTTL            time.Duration
+	RequireUserVerification bool
+}
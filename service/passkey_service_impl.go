@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"golang_jwt/audit"
+	"golang_jwt/exception"
+	"golang_jwt/helper"
+	"golang_jwt/model/domain"
+	"golang_jwt/model/web"
+	"golang_jwt/repository"
+	"golang_jwt/webauthn"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+type PasskeyServiceImpl struct {
+	UserRepository      repository.UserRepository
+	WebauthnRepository  repository.WebauthnRepository
+	DB                  *sql.DB
+	Validate            *validator.Validate
+	LoginLockoutService LoginLockoutService
+	SessionIssuer       SessionIssuer
+	AuditRecorder       audit.Recorder
+	WebhookService      WebhookService
+	RelyingParty        webauthn.RelyingParty
+	Config              PasskeyConfig
+}
+
+func NewPasskeyService(userRepository repository.UserRepository, webauthnRepository repository.WebauthnRepository, DB *sql.DB, Validate *validator.Validate, loginLockoutService LoginLockoutService, sessionIssuer SessionIssuer, auditRecorder audit.Recorder, webhookService WebhookService, config PasskeyConfig) PasskeyService {
+	return &PasskeyServiceImpl{
+		UserRepository:      userRepository,
+		WebauthnRepository:  webauthnRepository,
+		DB:                  DB,
+		Validate:            Validate,
+		LoginLockoutService: loginLockoutService,
+		SessionIssuer:       sessionIssuer,
+		AuditRecorder:       auditRecorder,
+		WebhookService:      webhookService,
+		RelyingParty: webauthn.RelyingParty{
+			ID:                      config.RPID,
+			Name:                    config.RPName,
+			Origins:                 config.Origins,
+			Timeout:                 config.ChallengeTTL,
+			RequireUserVerification: config.RequireUserVerification,
+		},
+		Config: config,
+	}
+}
+
+func (service *PasskeyServiceImpl) BeginRegistration(ctx context.Context, userId int) web.PasskeyRegistrationOptionsResponse {
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	user, err := service.UserRepository.FindById(ctx, tx, userId)
+	if err != nil {
+		panic(exception.NewNotFoundError(err.Error()))
+	}
+
+	credentials := service.WebauthnRepository.FindCredentialsByUserId(ctx, tx, user.ID)
+	challenge := service.saveChallenge(ctx, tx, &user.ID, domain.WebauthnCeremonyRegistration)
+
+	userEntity := webauthn.UserEntity{
+		ID:          userHandle(user.ID),
+		Name:        user.Username,
+		DisplayName: user.Email,
+	}
+
+	return web.PasskeyRegistrationOptionsResponse{
+		ChallengeId: challenge.ID,
+		PublicKey:   service.RelyingParty.NewCreationOptions(challenge.Challenge, userEntity, toCredentialDescriptors(credentials)),
+	}
+}
+
+func (service *PasskeyServiceImpl) FinishRegistration(ctx context.Context, userId int, request web.PasskeyRegistrationRequest) web.PasskeyResponse {
+	err := service.Validate.Struct(request)
+	helper.ErrorConditionCheck(err)
+
+	challenge, err := service.takeChallenge(ctx, request.ChallengeId, domain.WebauthnCeremonyRegistration)
+	if err != nil || challenge.UserID == nil || *challenge.UserID != userId {
+		panic(exception.NewNotFoundError("registration challenge not found or expired"))
+	}
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	credential, err := service.RelyingParty.VerifyRegistration(challenge.Challenge, request.Credential)
+	if err != nil {
+		panic(passkeyValidationError(err))
+	}
+
+	credentialId := webauthn.Encoding.EncodeToString(credential.ID)
+	_, err = service.WebauthnRepository.FindCredentialById(ctx, tx, credentialId)
+	if err == nil {
+		panic(exception.NewValidationError([]web.FieldError{{
+			Field:   "credential",
+			Rule:    "unique",
+			Message: "this passkey is already registered",
+		}}))
+	}
+
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		name = "Passkey"
+	}
+
+	savedCredential := service.WebauthnRepository.SaveCredential(ctx, tx, domain.WebauthnCredential{
+		ID:         credentialId,
+		UserID:     userId,
+		Name:       name,
+		PublicKey:  credential.PublicKey,
+		SignCount:  int64(credential.SignCount),
+		AAGUID:     uuidString(credential.AAGUID),
+		Transports: strings.Join(credential.Transports, ","),
+	})
+
+	return helper.ToPasskeyResponse(savedCredential)
+}
+
+func (service *PasskeyServiceImpl) FindAll(ctx context.Context, userId int) []web.PasskeyResponse {
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	credentials := service.WebauthnRepository.FindCredentialsByUserId(ctx, tx, userId)
+	return helper.ToPasskeyResponses(credentials)
+}
+
+func (service *PasskeyServiceImpl) Delete(ctx context.Context, userId int, credentialId string) {
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	err = service.WebauthnRepository.DeleteCredential(ctx, tx, credentialId, userId)
+	if err != nil {
+		panic(exception.NewNotFoundError(err.Error()))
+	}
+}
+
+// BeginLogin never reveals whether the identifier exists or which passkeys
+// it has: allowCredentials is always empty, so the authenticator offers its
+// discoverable credentials. A known identifier only binds the challenge to
+// that account.
+func (service *PasskeyServiceImpl) BeginLogin(ctx context.Context, request web.PasskeyLoginOptionsRequest) web.PasskeyLoginOptionsResponse {
+	err := service.Validate.Struct(request)
+	helper.ErrorConditionCheck(err)
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	var userId *int
+	if request.Identifier != "" {
+		user, err := findUserByIdentifier(ctx, tx, service.UserRepository, request.Identifier)
+		if err == nil {
+			userId = &user.ID
+		}
+	}
+
+	challenge := service.saveChallenge(ctx, tx, userId, domain.WebauthnCeremonyAuthentication)
+
+	return web.PasskeyLoginOptionsResponse{
+		ChallengeId: challenge.ID,
+		PublicKey:   service.RelyingParty.NewRequestOptions(challenge.Challenge, []webauthn.CredentialDescriptor{}),
+	}
+}
+
+// FinishLogin verifies the assertion and issues a session directly. A passkey
+// already combines possession with user verification, so no TOTP step follows.
+func (service *PasskeyServiceImpl) FinishLogin(ctx context.Context, request web.PasskeyLoginRequest) web.UserLoginResponse {
+	err := service.Validate.Struct(request)
+	helper.ErrorConditionCheck(err)
+
+	challenge, err := service.takeChallenge(ctx, request.ChallengeId, domain.WebauthnCeremonyAuthentication)
+	if err != nil {
+		service.rejectLogin(ctx, 0, "invalid_challenge")
+	}
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	credential, err := service.WebauthnRepository.FindCredentialById(ctx, tx, request.Credential.RawID)
+	if err != nil {
+		service.rejectLogin(ctx, 0, "unknown_credential")
+	}
+	if challenge.UserID != nil && *challenge.UserID != credential.UserID {
+		service.rejectLogin(ctx, credential.UserID, "credential_mismatch")
+	}
+	if request.Credential.Response.UserHandle != "" && request.Credential.Response.UserHandle != userHandle(credential.UserID) {
+		service.rejectLogin(ctx, credential.UserID, "credential_mismatch")
+	}
+
+	user, err := service.UserRepository.FindById(ctx, tx, credential.UserID)
+	if err != nil {
+		service.rejectLogin(ctx, 0, "unknown_account")
+	}
+
+	accountKey := AccountKeyForUser(user.ID)
+	service.LoginLockoutService.CheckAllowed(ctx, accountKey)
+
+	signCount, err := service.RelyingParty.VerifyAssertion(challenge.Challenge, credential.PublicKey, uint32(credential.SignCount), request.Credential)
+	if err != nil {
+		reason := "invalid_assertion"
+		if errors.Is(err, webauthn.ErrSignCountRegression) {
+			log.Printf("Passkey %s of user %d reported a non-increasing signature counter", credential.ID, user.ID)
+			reason = "sign_count_regression"
+		}
+		service.LoginLockoutService.RecordFailure(ctx, &user, accountKey)
+		service.rejectLogin(ctx, user.ID, reason)
+	}
+	service.LoginLockoutService.RecordSuccess(ctx, accountKey)
+
+	service.WebauthnRepository.UpdateCredentialUsage(ctx, tx, credential.ID, int64(signCount))
+
+	return service.SessionIssuer.IssueSession(ctx, tx, user, service.authentication())
+}
+
+// rejectLogin records the failed login and answers with the same error for
+// every reason, so callers cannot tell which check failed.
+func (service *PasskeyServiceImpl) rejectLogin(ctx context.Context, userId int, reason string) {
+	recordLoginFailure(ctx, service.AuditRecorder, service.WebhookService, web.AmrHardwareKey, userId, reason)
+	panic(exception.NewUnauthorizedError("invalid credentials"))
+}
+
+// authentication counts a passkey as multi-factor only when user
+// verification (PIN or biometric) is enforced for every assertion.
+func (service *PasskeyServiceImpl) authentication() Authentication {
+	if service.Config.RequireUserVerification {
+		return NewAuthentication(web.AmrHardwareKey, web.AmrMultiFactor)
+	}
+	return NewAuthentication(web.AmrHardwareKey)
+}
+
+// takeChallenge consumes the challenge in its own transaction, so it stays
+// used up even when the verification that follows fails and rolls back.
+func (service *PasskeyServiceImpl) takeChallenge(ctx context.Context, challengeId string, ceremony string) (domain.WebauthnChallenge, error) {
+	// The id column is a uuid, so any other value would fail the query.
+	err := uuid.Validate(challengeId)
+	if err != nil {
+		return domain.WebauthnChallenge{}, err
+	}
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	return service.WebauthnRepository.TakeChallenge(ctx, tx, challengeId, ceremony)
+}
+
+func (service *PasskeyServiceImpl) saveChallenge(ctx context.Context, tx *sql.Tx, userId *int, ceremony string) domain.WebauthnChallenge {
+	challenge := make([]byte, 32)
+	_, err := rand.Read(challenge)
+	helper.ErrorConditionCheck(err)
+
+	return service.WebauthnRepository.SaveChallenge(ctx, tx, domain.WebauthnChallenge{
+		ID:        uuid.New().String(),
+		UserID:    userId,
+		Challenge: challenge,
+		Ceremony:  ceremony,
+		ExpiresAt: time.Now().Add(service.Config.ChallengeTTL),
+	})
+}
+
+// userHandle is the opaque WebAuthn user.id. It is derived from the user id
+// so no extra column is needed, and it contains no personal data.
+func userHandle(userId int) string {
+	return webauthn.Encoding.EncodeToString([]byte(strconv.Itoa(userId)))
+}
+
+func toCredentialDescriptors(credentials []domain.WebauthnCredential) []webauthn.CredentialDescriptor {
+	descriptors := []webauthn.CredentialDescriptor{}
+	for _, credential := range credentials {
+		descriptor := webauthn.CredentialDescriptor{Type: webauthn.CredentialType, ID: credential.ID}
+		if credential.Transports != "" {
+			descriptor.Transports = strings.Split(credential.Transports, ",")
+		}
+		descriptors = append(descriptors, descriptor)
+	}
+	return descriptors
+}
+
+func uuidString(value []byte) string {
+	parsed, err := uuid.FromBytes(value)
+	if err != nil {
+		return ""
+	}
+	return parsed.String()
+}
+
+func passkeyValidationError(err error) exception.ValidationError {
+	return exception.NewValidationError([]web.FieldError{{
+		Field:   "credential",
+		Rule:    "webauthn",
+		Message: strings.TrimPrefix(err.Error(), "webauthn: "),
+	}})
+}
@@ -0,0 +1,461 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"golang_jwt/audit"
+	"golang_jwt/exception"
+	"golang_jwt/model/domain"
+	"golang_jwt/model/web"
+	"golang_jwt/repository"
+	"golang_jwt/webauthn"
+	"golang_jwt/webauthn/webauthntest"
+	"golang_jwt/webhook"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// journalDriver is a database/sql driver that only knows transactions. The
+// fake repositories below register an undo function for every change, which
+// runs when the transaction rolls back, so tests see the same commit and
+// rollback behaviour as with Postgres. Transactions must not overlap.
+type journalDriver struct{}
+
+type journalConn struct{}
+
+type journalTx struct{}
+
+var journal struct {
+	sync.Mutex
+	undo []func()
+}
+
+func init() {
+	sql.Register("journal", journalDriver{})
+}
+
+func (journalDriver) Open(name string) (driver.Conn, error) {
+	return journalConn{}, nil
+}
+
+func (journalConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("journal: statements are not supported")
+}
+
+func (journalConn) Close() error {
+	return nil
+}
+
+func (journalConn) Begin() (driver.Tx, error) {
+	journal.Lock()
+	defer journal.Unlock()
+	journal.undo = nil
+	return journalTx{}, nil
+}
+
+func (journalTx) Commit() error {
+	journal.Lock()
+	defer journal.Unlock()
+	journal.undo = nil
+	return nil
+}
+
+func (journalTx) Rollback() error {
+	journal.Lock()
+	defer journal.Unlock()
+	for i := len(journal.undo) - 1; i >= 0; i-- {
+		journal.undo[i]()
+	}
+	journal.undo = nil
+	return nil
+}
+
+func onRollback(undo func()) {
+	journal.Lock()
+	defer journal.Unlock()
+	journal.undo = append(journal.undo, undo)
+}
+
+type fakeUserRepository struct {
+	repository.UserRepository
+	users []domain.User
+}
+
+func (repository *fakeUserRepository) find(match func(user domain.User) bool) (domain.User, error) {
+	index := slices.IndexFunc(repository.users, match)
+	if index < 0 {
+		return domain.User{}, errors.New("user not found")
+	}
+	return repository.users[index], nil
+}
+
+func (repository *fakeUserRepository) FindById(ctx context.Context, tx *sql.Tx, userId int) (domain.User, error) {
+	return repository.find(func(user domain.User) bool { return user.ID == userId })
+}
+
+func (repository *fakeUserRepository) FindByEmail(ctx context.Context, tx *sql.Tx, email string) (domain.User, error) {
+	return repository.find(func(user domain.User) bool { return user.Email == email })
+}
+
+func (repository *fakeUserRepository) FindByUsername(ctx context.Context, tx *sql.Tx, username string) (domain.User, error) {
+	return repository.find(func(user domain.User) bool { return user.Username == username })
+}
+
+type fakeWebauthnRepository struct {
+	credentials map[string]domain.WebauthnCredential
+	challenges  map[string]domain.WebauthnChallenge
+}
+
+func newFakeWebauthnRepository() *fakeWebauthnRepository {
+	return &fakeWebauthnRepository{
+		credentials: map[string]domain.WebauthnCredential{},
+		challenges:  map[string]domain.WebauthnChallenge{},
+	}
+}
+
+func (repository *fakeWebauthnRepository) SaveCredential(ctx context.Context, tx *sql.Tx, credential domain.WebauthnCredential) domain.WebauthnCredential {
+	credential.CreatedAt = time.Now()
+	repository.credentials[credential.ID] = credential
+	onRollback(func() { delete(repository.credentials, credential.ID) })
+	return credential
+}
+
+func (repository *fakeWebauthnRepository) FindCredentialById(ctx context.Context, tx *sql.Tx, id string) (domain.WebauthnCredential, error) {
+	credential, ok := repository.credentials[id]
+	if !ok {
+		return credential, errors.New("passkey not found")
+	}
+	return credential, nil
+}
+
+func (repository *fakeWebauthnRepository) FindCredentialsByUserId(ctx context.Context, tx *sql.Tx, userId int) []domain.WebauthnCredential {
+	var credentials []domain.WebauthnCredential
+	for _, credential := range repository.credentials {
+		if credential.UserID == userId {
+			credentials = append(credentials, credential)
+		}
+	}
+	return credentials
+}
+
+func (repository *fakeWebauthnRepository) UpdateCredentialUsage(ctx context.Context, tx *sql.Tx, id string, signCount int64) error {
+	credential, ok := repository.credentials[id]
+	if !ok {
+		return errors.New("passkey not found")
+	}
+	previous := credential
+	now := time.Now()
+	credential.SignCount = signCount
+	credential.LastUsedAt = &now
+	repository.credentials[id] = credential
+	onRollback(func() { repository.credentials[id] = previous })
+	return nil
+}
+
+func (repository *fakeWebauthnRepository) DeleteCredential(ctx context.Context, tx *sql.Tx, id string, userId int) error {
+	credential, ok := repository.credentials[id]
+	if !ok || credential.UserID != userId {
+		return errors.New("passkey not found")
+	}
+	delete(repository.credentials, id)
+	onRollback(func() { repository.credentials[id] = credential })
+	return nil
+}
+
+func (repository *fakeWebauthnRepository) SaveChallenge(ctx context.Context, tx *sql.Tx, challenge domain.WebauthnChallenge) domain.WebauthnChallenge {
+	repository.challenges[challenge.ID] = challenge
+	onRollback(func() { delete(repository.challenges, challenge.ID) })
+	return challenge
+}
+
+func (repository *fakeWebauthnRepository) TakeChallenge(ctx context.Context, tx *sql.Tx, id string, ceremony string) (domain.WebauthnChallenge, error) {
+	challenge, ok := repository.challenges[id]
+	if !ok || challenge.Ceremony != ceremony || time.Now().After(challenge.ExpiresAt) {
+		return challenge, errors.New("challenge not found")
+	}
+	delete(repository.challenges, id)
+	onRollback(func() { repository.challenges[id] = challenge })
+	return challenge, nil
+}
+
+type fakeLoginLockoutService struct {
+	LoginLockoutService
+	failures  int
+	successes int
+}
+
+func (service *fakeLoginLockoutService) CheckAllowed(ctx context.Context, accountKey string) {
+}
+
+func (service *fakeLoginLockoutService) RecordFailure(ctx context.Context, user *domain.User, accountKey string) {
+	service.failures++
+}
+
+func (service *fakeLoginLockoutService) RecordSuccess(ctx context.Context, accountKey string) {
+	service.successes++
+}
+
+type fakeSessionIssuer struct {
+	SessionIssuer
+	sessions []Authentication
+}
+
+func (issuer *fakeSessionIssuer) IssueSession(ctx context.Context, tx *sql.Tx, user domain.User, authentication Authentication) web.UserLoginResponse {
+	issuer.sessions = append(issuer.sessions, authentication)
+	return web.UserLoginResponse{AccessToken: "access-token", TokenType: "Bearer"}
+}
+
+type fakeAuditRecorder struct {
+	events []audit.Event
+}
+
+func (recorder *fakeAuditRecorder) Record(ctx context.Context, event audit.Event) error {
+	recorder.events = append(recorder.events, event)
+	return nil
+}
+
+type fakeWebhookService struct {
+	WebhookService
+	events []webhook.EventType
+}
+
+func (service *fakeWebhookService) PublishSeparately(ctx context.Context, eventType webhook.EventType, data map[string]interface{}) {
+	service.events = append(service.events, eventType)
+}
+
+type passkeyTest struct {
+	service       PasskeyService
+	credentials   *fakeWebauthnRepository
+	lockout       *fakeLoginLockoutService
+	sessions      *fakeSessionIssuer
+	audit         *fakeAuditRecorder
+	webhooks      *fakeWebhookService
+	authenticator *webauthntest.Authenticator
+}
+
+func newPasskeyTest(t *testing.T) *passkeyTest {
+	DB, err := sql.Open("journal", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { DB.Close() })
+
+	test := &passkeyTest{
+		credentials:   newFakeWebauthnRepository(),
+		lockout:       &fakeLoginLockoutService{},
+		sessions:      &fakeSessionIssuer{},
+		audit:         &fakeAuditRecorder{},
+		webhooks:      &fakeWebhookService{},
+		authenticator: webauthntest.NewAuthenticator("example.com", "https://example.com"),
+	}
+	userRepository := &fakeUserRepository{users: []domain.User{
+		{ID: 1, Username: "arthur", Email: "arthur@example.com"},
+		{ID: 2, Username: "ford", Email: "ford@example.com"},
+	}}
+	test.service = NewPasskeyService(userRepository, test.credentials, DB, validator.New(), test.lockout, test.sessions, test.audit, test.webhooks, PasskeyConfig{
+		RPID:                    "example.com",
+		RPName:                  "Example",
+		Origins:                 []string{"https://example.com"},
+		ChallengeTTL:            5 * time.Minute,
+		RequireUserVerification: true,
+	})
+	return test
+}
+
+// register adds the test authenticator's passkey to user 1.
+func (test *passkeyTest) register(t *testing.T) web.PasskeyResponse {
+	t.Helper()
+	options := test.service.BeginRegistration(context.Background(), 1)
+	test.authenticator.UserHandle = options.PublicKey.User.ID
+	return test.service.FinishRegistration(context.Background(), 1, web.PasskeyRegistrationRequest{
+		ChallengeId: options.ChallengeId,
+		Name:        "Laptop",
+		Credential:  test.authenticator.Register(options.PublicKey),
+	})
+}
+
+func expectPanic[T any](t *testing.T, call func()) T {
+	t.Helper()
+	var recovered interface{}
+	func() {
+		defer func() { recovered = recover() }()
+		call()
+	}()
+
+	err, ok := recovered.(T)
+	if !ok {
+		var want T
+		t.Fatalf("recovered %#v, want a %T", recovered, want)
+	}
+	return err
+}
+
+func TestPasskeyLoginRoundTrip(t *testing.T) {
+	test := newPasskeyTest(t)
+
+	passkey := test.register(t)
+	if passkey.Name != "Laptop" || passkey.Id != webauthn.Encoding.EncodeToString(test.authenticator.CredentialID) {
+		t.Fatalf("FinishRegistration = %+v, want the authenticator's credential named Laptop", passkey)
+	}
+
+	options := test.service.BeginLogin(context.Background(), web.PasskeyLoginOptionsRequest{Identifier: "arthur"})
+	response := test.service.FinishLogin(context.Background(), web.PasskeyLoginRequest{
+		ChallengeId: options.ChallengeId,
+		Credential:  test.authenticator.Login(options.PublicKey),
+	})
+
+	if response.AccessToken != "access-token" {
+		t.Errorf("FinishLogin = %+v, want the issued session", response)
+	}
+	if len(test.sessions.sessions) != 1 || !slices.Contains(test.sessions.sessions[0].Methods, web.AmrMultiFactor) {
+		t.Errorf("sessions issued = %+v, want one multi-factor session", test.sessions.sessions)
+	}
+	if test.lockout.successes != 1 || test.lockout.failures != 0 {
+		t.Errorf("lockout recorded %d successes and %d failures, want 1 and 0", test.lockout.successes, test.lockout.failures)
+	}
+	credential := test.credentials.credentials[passkey.Id]
+	if credential.SignCount != 1 || credential.LastUsedAt == nil {
+		t.Errorf("stored credential = %+v, want sign count 1 and a last use", credential)
+	}
+}
+
+func TestPasskeyBeginLoginDoesNotRevealCredentials(t *testing.T) {
+	test := newPasskeyTest(t)
+	test.register(t)
+
+	for _, identifier := range []string{"", "arthur", "arthur@example.com", "ford", "nobody"} {
+		options := test.service.BeginLogin(context.Background(), web.PasskeyLoginOptionsRequest{Identifier: identifier})
+		if options.PublicKey.AllowCredentials == nil || len(options.PublicKey.AllowCredentials) != 0 {
+			t.Errorf("BeginLogin(%q) allowCredentials = %#v, want an empty list", identifier, options.PublicKey.AllowCredentials)
+		}
+	}
+}
+
+func TestPasskeyFinishLoginRejectsReplayedChallenge(t *testing.T) {
+	test := newPasskeyTest(t)
+	test.register(t)
+
+	options := test.service.BeginLogin(context.Background(), web.PasskeyLoginOptionsRequest{})
+	request := web.PasskeyLoginRequest{
+		ChallengeId: options.ChallengeId,
+		Credential:  test.authenticator.Login(options.PublicKey),
+	}
+	test.service.FinishLogin(context.Background(), request)
+
+	request.Credential = test.authenticator.Login(options.PublicKey)
+	expectPanic[exception.UnauthorizedError](t, func() {
+		test.service.FinishLogin(context.Background(), request)
+	})
+}
+
+func TestPasskeyFinishLoginConsumesChallengeWhenVerificationFails(t *testing.T) {
+	test := newPasskeyTest(t)
+	test.register(t)
+
+	options := test.service.BeginLogin(context.Background(), web.PasskeyLoginOptionsRequest{})
+	test.authenticator.Origin = "https://evil.example"
+	expectPanic[exception.UnauthorizedError](t, func() {
+		test.service.FinishLogin(context.Background(), web.PasskeyLoginRequest{
+			ChallengeId: options.ChallengeId,
+			Credential:  test.authenticator.Login(options.PublicKey),
+		})
+	})
+	if test.lockout.failures != 1 {
+		t.Errorf("lockout recorded %d failures, want 1", test.lockout.failures)
+	}
+
+	test.authenticator.Origin = "https://example.com"
+	expectPanic[exception.UnauthorizedError](t, func() {
+		test.service.FinishLogin(context.Background(), web.PasskeyLoginRequest{
+			ChallengeId: options.ChallengeId,
+			Credential:  test.authenticator.Login(options.PublicKey),
+		})
+	})
+}
+
+func TestPasskeyFinishLoginRejectsChallengeBoundToAnotherUser(t *testing.T) {
+	test := newPasskeyTest(t)
+	test.register(t)
+
+	options := test.service.BeginLogin(context.Background(), web.PasskeyLoginOptionsRequest{Identifier: "ford"})
+	expectPanic[exception.UnauthorizedError](t, func() {
+		test.service.FinishLogin(context.Background(), web.PasskeyLoginRequest{
+			ChallengeId: options.ChallengeId,
+			Credential:  test.authenticator.Login(options.PublicKey),
+		})
+	})
+}
+
+func TestPasskeyFinishLoginRejectsSignCountRegression(t *testing.T) {
+	test := newPasskeyTest(t)
+	test.register(t)
+	test.authenticator.SignCount = 5
+
+	options := test.service.BeginLogin(context.Background(), web.PasskeyLoginOptionsRequest{})
+	test.service.FinishLogin(context.Background(), web.PasskeyLoginRequest{
+		ChallengeId: options.ChallengeId,
+		Credential:  test.authenticator.Login(options.PublicKey),
+	})
+
+	test.authenticator.SignCount = 2
+	options = test.service.BeginLogin(context.Background(), web.PasskeyLoginOptionsRequest{})
+	expectPanic[exception.UnauthorizedError](t, func() {
+		test.service.FinishLogin(context.Background(), web.PasskeyLoginRequest{
+			ChallengeId: options.ChallengeId,
+			Credential:  test.authenticator.Login(options.PublicKey),
+		})
+	})
+
+	if len(test.audit.events) != 1 {
+		t.Fatalf("audit events = %+v, want one failed login", test.audit.events)
+	}
+	event := test.audit.events[0]
+	if event.Type != audit.EventLogin || event.Outcome != audit.OutcomeFailure || event.UserID != 1 || event.Metadata["reason"] != "sign_count_regression" {
+		t.Errorf("audit event = %+v, want a failed login of user 1 for sign_count_regression", event)
+	}
+	if !slices.Equal(test.webhooks.events, []webhook.EventType{webhook.EventLoginFailed}) {
+		t.Errorf("webhook events = %v, want one %s", test.webhooks.events, webhook.EventLoginFailed)
+	}
+}
+
+func TestPasskeyFinishRegistrationConsumesChallengeWhenVerificationFails(t *testing.T) {
+	test := newPasskeyTest(t)
+
+	options := test.service.BeginRegistration(context.Background(), 1)
+	test.authenticator.RPID = "evil.example"
+	expectPanic[exception.ValidationError](t, func() {
+		test.service.FinishRegistration(context.Background(), 1, web.PasskeyRegistrationRequest{
+			ChallengeId: options.ChallengeId,
+			Credential:  test.authenticator.Register(options.PublicKey),
+		})
+	})
+
+	test.authenticator.RPID = "example.com"
+	expectPanic[exception.NotFoundError](t, func() {
+		test.service.FinishRegistration(context.Background(), 1, web.PasskeyRegistrationRequest{
+			ChallengeId: options.ChallengeId,
+			Credential:  test.authenticator.Register(options.PublicKey),
+		})
+	})
+	if len(test.credentials.credentials) != 0 {
+		t.Errorf("stored credentials = %+v, want none", test.credentials.credentials)
+	}
+}
+
+func TestPasskeyFinishRegistrationRejectsMalformedChallengeId(t *testing.T) {
+	test := newPasskeyTest(t)
+
+	options := test.service.BeginRegistration(context.Background(), 1)
+	expectPanic[validator.ValidationErrors](t, func() {
+		test.service.FinishRegistration(context.Background(), 1, web.PasskeyRegistrationRequest{
+			ChallengeId: "not-a-uuid",
+			Credential:  test.authenticator.Register(options.PublicKey),
+		})
+	})
+}
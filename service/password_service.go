@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+	"golang_jwt/model/web"
+	"time"
+)
+
+type PasswordService interface {
+	ChangePassword(ctx context.Context, userId int, request web.ChangePasswordRequest)
+	ForgotPassword(ctx context.Context, request web.ForgotPasswordRequest)
+	ResetPassword(ctx context.Context, request web.ResetPasswordRequest)
+}
+
+type PasswordResetConfig struct {
+	BaseURL  string
+	TokenTTL time.Duration
+}
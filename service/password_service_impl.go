@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"golang_jwt/exception"
+	"golang_jwt/hasher"
+	"golang_jwt/helper"
+	"golang_jwt/mailer"
+	"golang_jwt/model/domain"
+	"golang_jwt/model/web"
+	"golang_jwt/policy"
+	"golang_jwt/repository"
+	"golang_jwt/webhook"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type PasswordServiceImpl struct {
+	UserRepository          repository.UserRepository
+	SessionStore            repository.SessionStore
+	PasswordResetRepository repository.PasswordResetRepository
+	DB                      *sql.DB
+	Validate                *validator.Validate
+	PasswordHasher          hasher.PasswordHasher
+	PasswordPolicy          policy.PasswordPolicy
+	TrustedDeviceService    TrustedDeviceService
+	Mailer                  mailer.Mailer
+	WebhookService          WebhookService
+	Config                  PasswordResetConfig
+}
+
+func NewPasswordService(userRepository repository.UserRepository, sessionStore repository.SessionStore, passwordResetRepository repository.PasswordResetRepository, DB *sql.DB, Validate *validator.Validate, passwordHasher hasher.PasswordHasher, passwordPolicy policy.PasswordPolicy, trustedDeviceService TrustedDeviceService, mailer mailer.Mailer, webhookService WebhookService, config PasswordResetConfig) PasswordService {
+	return &PasswordServiceImpl{
+		UserRepository:          userRepository,
+		SessionStore:            sessionStore,
+		PasswordResetRepository: passwordResetRepository,
+		DB:                      DB,
+		Validate:                Validate,
+		PasswordHasher:          passwordHasher,
+		PasswordPolicy:          passwordPolicy,
+		TrustedDeviceService:    trustedDeviceService,
+		Mailer:                  mailer,
+		WebhookService:          webhookService,
+		Config:                  config,
+	}
+}
+
+func (service *PasswordServiceImpl) ChangePassword(ctx context.Context, userId int, request web.ChangePasswordRequest) {
+	err := service.Validate.Struct(request)
+	helper.ErrorConditionCheck(err)
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	user, err := service.UserRepository.FindById(ctx, tx, userId)
+	if err != nil {
+		panic(exception.NewNotFoundError(err.Error()))
+	}
+
+	valid, err := service.PasswordHasher.Verify(ctx, user.Password, request.CurrentPassword)
+	hasherErrorCheck(err)
+	if !valid {
+		panic(exception.NewValidationError([]web.FieldError{{
+			Field:   "current_password",
+			Rule:    "invalid",
+			Message: "current password is incorrect",
+		}}))
+	}
+
+	service.updatePassword(ctx, tx, user, "new_password", request.NewPassword)
+	service.WebhookService.Publish(ctx, tx, webhook.EventPasswordChanged, map[string]interface{}{
+		"user_id": user.ID,
+		"reason":  "change",
+	})
+}
+
+// ForgotPassword always succeeds from the caller's point of view so the
+// response does not reveal whether the email belongs to an account. The
+// email is sent in the background, so its latency does not reveal it either.
+func (service *PasswordServiceImpl) ForgotPassword(ctx context.Context, request web.ForgotPasswordRequest) {
+	err := service.Validate.Struct(request)
+	helper.ErrorConditionCheck(err)
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	user, err := service.UserRepository.FindByEmail(ctx, tx, request.Email)
+	if err != nil {
+		return
+	}
+
+	token := helper.GenerateRandomToken(32)
+	service.PasswordResetRepository.Save(ctx, tx, domain.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(service.Config.TokenTTL),
+	})
+
+	sendMailAsync(ctx, service.Mailer, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone requested a password reset for your account.\n\nUse the link below within %s to choose a new password:\n%s/reset-password?token=%s\n\nIf this wasn't you, you can ignore this email.",
+			service.Config.TokenTTL, service.Config.BaseURL, token),
+	}, "password reset email")
+}
+
+func (service *PasswordServiceImpl) ResetPassword(ctx context.Context, request web.ResetPasswordRequest) {
+	err := service.Validate.Struct(request)
+	helper.ErrorConditionCheck(err)
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	passwordReset, err := service.PasswordResetRepository.FindByTokenHash(ctx, tx, helper.HashToken(request.Token))
+	if err != nil || passwordReset.UsedAt != nil || time.Now().After(passwordReset.ExpiresAt) {
+		panic(exception.NewNotFoundError("reset token is invalid or expired"))
+	}
+
+	user, err := service.UserRepository.FindById(ctx, tx, passwordReset.UserID)
+	if err != nil {
+		panic(exception.NewNotFoundError(err.Error()))
+	}
+
+	service.updatePassword(ctx, tx, user, "new_password", request.NewPassword)
+	service.PasswordResetRepository.MarkUsed(ctx, tx, passwordReset.ID)
+	service.SessionStore.RevokeAllForUser(ctx, user.Email)
+	service.WebhookService.Publish(ctx, tx, webhook.EventPasswordChanged, map[string]interface{}{
+		"user_id":          user.ID,
+		"reason":           "reset",
+		"sessions_revoked": true,
+	})
+}
+
+func (service *PasswordServiceImpl) updatePassword(ctx context.Context, tx *sql.Tx, user domain.User, field string, password string) {
+	violations, err := service.PasswordPolicy.Validate(field, password, policy.PasswordIdentity{
+		Username: user.Username,
+		Email:    user.Email,
+	})
+	helper.ErrorConditionCheck(err)
+	if len(violations) > 0 {
+		panic(exception.NewValidationError(violations))
+	}
+
+	hashedPassword, err := service.PasswordHasher.Hash(ctx, password)
+	hasherErrorCheck(err)
+
+	service.UserRepository.UpdatePassword(ctx, tx, user.ID, hashedPassword)
+
+	// Whoever knew the old password must not keep skipping MFA either.
+	service.TrustedDeviceService.RevokeAll(ctx, tx, user.ID)
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"golang_jwt/model/web"
+	"time"
+)
+
+type PasswordlessService interface {
+	RequestLogin(ctx context.Context, request web.PasswordlessLoginRequest)
+	VerifyLogin(ctx context.Context, request web.PasswordlessVerifyRequest) web.UserLoginResponse
+}
+
+type PasswordlessConfig struct {
+	BaseURL         string
+	LinkTTL         time.Duration
+	CodeTTL         time.Duration
+	MaxCodeAttempts int
+	RateLimit       int
+	RateWindow      time.Duration
+}
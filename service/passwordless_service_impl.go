@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"fmt"
+	"golang_jwt/audit"
+	"golang_jwt/exception"
+	"golang_jwt/helper"
+	"golang_jwt/mailer"
+	"golang_jwt/model/domain"
+	"golang_jwt/model/web"
+	"golang_jwt/repository"
+	"math/big"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type PasswordlessServiceImpl struct {
+	UserRepository              repository.UserRepository
+	PasswordlessTokenRepository repository.PasswordlessTokenRepository
+	DB                          *sql.DB
+	Validate                    *validator.Validate
+	LoginLockoutService         LoginLockoutService
+	SessionIssuer               SessionIssuer
+	TrustedDeviceService        TrustedDeviceService
+	AuditRecorder               audit.Recorder
+	WebhookService              WebhookService
+	Mailer                      mailer.Mailer
+	Config                      PasswordlessConfig
+}
+
+func NewPasswordlessService(userRepository repository.UserRepository, passwordlessTokenRepository repository.PasswordlessTokenRepository, DB *sql.DB, Validate *validator.Validate, loginLockoutService LoginLockoutService, sessionIssuer SessionIssuer, trustedDeviceService TrustedDeviceService, auditRecorder audit.Recorder, webhookService WebhookService, mailer mailer.Mailer, config PasswordlessConfig) PasswordlessService {
+	return &PasswordlessServiceImpl{
+		UserRepository:              userRepository,
+		PasswordlessTokenRepository: passwordlessTokenRepository,
+		DB:                          DB,
+		Validate:                    Validate,
+		LoginLockoutService:         loginLockoutService,
+		SessionIssuer:               sessionIssuer,
+		TrustedDeviceService:        trustedDeviceService,
+		AuditRecorder:               auditRecorder,
+		WebhookService:              webhookService,
+		Mailer:                      mailer,
+		Config:                      config,
+	}
+}
+
+// RequestLogin answers the same way for known and unknown addresses. Unknown
+// addresses still get a token row so they are rate limited identically, but
+// no email is sent to them; the email to known ones is sent in the
+// background so both take the same time.
+func (service *PasswordlessServiceImpl) RequestLogin(ctx context.Context, request web.PasswordlessLoginRequest) {
+	err := service.Validate.Struct(request)
+	helper.ErrorConditionCheck(err)
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	count, oldest := service.PasswordlessTokenRepository.CountSince(ctx, tx, request.Email, time.Now().Add(-service.Config.RateWindow))
+	if count >= service.Config.RateLimit {
+		panic(exception.NewTooManyRequestsError("too many login requests for this address", time.Until(oldest.Add(service.Config.RateWindow))))
+	}
+
+	var userId *int
+	user, err := service.UserRepository.FindByEmail(ctx, tx, request.Email)
+	if err == nil {
+		userId = &user.ID
+	}
+
+	var secret string
+	var ttl time.Duration
+	if request.Method == domain.PasswordlessMethodCode {
+		secret, ttl = generateLoginCode(), service.Config.CodeTTL
+	} else {
+		secret, ttl = helper.GenerateRandomToken(32), service.Config.LinkTTL
+	}
+
+	service.PasswordlessTokenRepository.InvalidateActive(ctx, tx, request.Email, request.Method)
+	service.PasswordlessTokenRepository.Save(ctx, tx, domain.PasswordlessToken{
+		Email:     request.Email,
+		UserID:    userId,
+		Method:    request.Method,
+		TokenHash: helper.HashToken(secret),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+
+	if userId == nil {
+		return
+	}
+
+	message := mailer.Message{
+		To:      user.Email,
+		Subject: "Your sign-in link",
+		Body: fmt.Sprintf("Use the link below within %s to sign in:\n%s/login/passwordless?token=%s\n\nIf this wasn't you, you can ignore this email.",
+			ttl, service.Config.BaseURL, secret),
+	}
+	if request.Method == domain.PasswordlessMethodCode {
+		message.Subject = "Your sign-in code"
+		message.Body = fmt.Sprintf("Your sign-in code is %s. It expires in %s.\n\nIf this wasn't you, you can ignore this email.", secret, ttl)
+	}
+
+	sendMailAsync(ctx, service.Mailer, message, "passwordless login email")
+}
+
+// VerifyLogin redeems a link token or code and then behaves like Login: a
+// session is issued, or an MFA challenge when TOTP is enabled and the device
+// is not trusted.
+func (service *PasswordlessServiceImpl) VerifyLogin(ctx context.Context, request web.PasswordlessVerifyRequest) web.UserLoginResponse {
+	err := service.Validate.Struct(request)
+	helper.ErrorConditionCheck(err)
+
+	userId, valid := service.redeem(ctx, request)
+	if !valid {
+		reason := "invalid_code"
+		if request.Token != "" {
+			reason = "invalid_token"
+		}
+		recordLoginFailure(ctx, service.AuditRecorder, service.WebhookService, web.AmrEmail, userId, reason)
+		panic(exception.NewUnauthorizedError("invalid or expired login token"))
+	}
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	user, err := service.UserRepository.FindById(ctx, tx, userId)
+	if err != nil {
+		panic(exception.NewUnauthorizedError("invalid or expired login token"))
+	}
+
+	authentication := NewAuthentication(web.AmrEmail)
+	if user.TotpEnabled && !service.TrustedDeviceService.IsTrusted(ctx, tx, user.ID, request.DeviceToken) {
+		return service.SessionIssuer.IssueMfaChallenge(user, authentication)
+	}
+
+	return service.SessionIssuer.IssueSession(ctx, tx, user, authentication)
+}
+
+// redeem commits its own transaction before VerifyLogin reports a failure,
+// so failed code attempts are counted even though the request ends in an
+// error. A failed code attempt still returns the account it was made for.
+func (service *PasswordlessServiceImpl) redeem(ctx context.Context, request web.PasswordlessVerifyRequest) (int, bool) {
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	if request.Token != "" {
+		passwordlessToken, err := service.PasswordlessTokenRepository.FindByTokenHash(ctx, tx, helper.HashToken(request.Token))
+		if err != nil || passwordlessToken.UsedAt != nil || passwordlessToken.UserID == nil || time.Now().After(passwordlessToken.ExpiresAt) {
+			return 0, false
+		}
+		service.PasswordlessTokenRepository.MarkUsed(ctx, tx, passwordlessToken.ID)
+		return *passwordlessToken.UserID, true
+	}
+
+	passwordlessToken, err := service.PasswordlessTokenRepository.FindActiveCode(ctx, tx, request.Email)
+	if err != nil || passwordlessToken.UserID == nil {
+		return 0, false
+	}
+
+	accountKey := AccountKeyForUser(*passwordlessToken.UserID)
+	service.LoginLockoutService.CheckAllowed(ctx, accountKey)
+
+	if subtle.ConstantTimeCompare([]byte(helper.HashToken(request.Code)), []byte(passwordlessToken.TokenHash)) != 1 {
+		service.PasswordlessTokenRepository.IncrementAttempts(ctx, tx, passwordlessToken.ID)
+		if passwordlessToken.Attempts+1 >= service.Config.MaxCodeAttempts {
+			service.PasswordlessTokenRepository.MarkUsed(ctx, tx, passwordlessToken.ID)
+		}
+		service.LoginLockoutService.RecordFailure(ctx, nil, accountKey)
+		return *passwordlessToken.UserID, false
+	}
+	service.LoginLockoutService.RecordSuccess(ctx, accountKey)
+
+	service.PasswordlessTokenRepository.MarkUsed(ctx, tx, passwordlessToken.ID)
+	return *passwordlessToken.UserID, true
+}
+
+func generateLoginCode() string {
+	code, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	helper.ErrorConditionCheck(err)
+	return fmt.Sprintf("%06d", code.Int64())
+}
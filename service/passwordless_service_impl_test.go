@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"golang_jwt/exception"
+	"golang_jwt/mailer"
+	"golang_jwt/model/domain"
+	"golang_jwt/model/web"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type fakePasswordlessTokenRepository struct {
+	tokens []domain.PasswordlessToken
+}
+
+func (repository *fakePasswordlessTokenRepository) update(id int, change func(passwordlessToken *domain.PasswordlessToken)) {
+	for i := range repository.tokens {
+		if repository.tokens[i].ID == id {
+			previous := repository.tokens[i]
+			change(&repository.tokens[i])
+			onRollback(func() { repository.tokens[i] = previous })
+		}
+	}
+}
+
+func (repository *fakePasswordlessTokenRepository) Save(ctx context.Context, tx *sql.Tx, passwordlessToken domain.PasswordlessToken) domain.PasswordlessToken {
+	passwordlessToken.ID = len(repository.tokens) + 1
+	passwordlessToken.Email = strings.ToLower(passwordlessToken.Email)
+	passwordlessToken.CreatedAt = time.Now()
+	repository.tokens = append(repository.tokens, passwordlessToken)
+	onRollback(func() { repository.tokens = repository.tokens[:len(repository.tokens)-1] })
+	return passwordlessToken
+}
+
+func (repository *fakePasswordlessTokenRepository) FindByTokenHash(ctx context.Context, tx *sql.Tx, tokenHash string) (domain.PasswordlessToken, error) {
+	for _, passwordlessToken := range repository.tokens {
+		if passwordlessToken.TokenHash == tokenHash && passwordlessToken.Method == domain.PasswordlessMethodLink {
+			return passwordlessToken, nil
+		}
+	}
+	return domain.PasswordlessToken{}, errors.New("passwordless token not found")
+}
+
+func (repository *fakePasswordlessTokenRepository) FindActiveCode(ctx context.Context, tx *sql.Tx, email string) (domain.PasswordlessToken, error) {
+	for i := len(repository.tokens) - 1; i >= 0; i-- {
+		passwordlessToken := repository.tokens[i]
+		if passwordlessToken.Email == strings.ToLower(email) && passwordlessToken.Method == domain.PasswordlessMethodCode &&
+			passwordlessToken.UsedAt == nil && time.Now().Before(passwordlessToken.ExpiresAt) {
+			return passwordlessToken, nil
+		}
+	}
+	return domain.PasswordlessToken{}, errors.New("passwordless token not found")
+}
+
+func (repository *fakePasswordlessTokenRepository) IncrementAttempts(ctx context.Context, tx *sql.Tx, id int) error {
+	repository.update(id, func(passwordlessToken *domain.PasswordlessToken) { passwordlessToken.Attempts++ })
+	return nil
+}
+
+func (repository *fakePasswordlessTokenRepository) MarkUsed(ctx context.Context, tx *sql.Tx, id int) error {
+	now := time.Now()
+	repository.update(id, func(passwordlessToken *domain.PasswordlessToken) { passwordlessToken.UsedAt = &now })
+	return nil
+}
+
+func (repository *fakePasswordlessTokenRepository) InvalidateActive(ctx context.Context, tx *sql.Tx, email string, method string) error {
+	for _, passwordlessToken := range repository.tokens {
+		if passwordlessToken.Email == strings.ToLower(email) && passwordlessToken.Method == method && passwordlessToken.UsedAt == nil {
+			repository.MarkUsed(ctx, tx, passwordlessToken.ID)
+		}
+	}
+	return nil
+}
+
+func (repository *fakePasswordlessTokenRepository) CountSince(ctx context.Context, tx *sql.Tx, email string, since time.Time) (int, time.Time) {
+	count, oldest := 0, time.Now()
+	for _, passwordlessToken := range repository.tokens {
+		if passwordlessToken.Email == strings.ToLower(email) && passwordlessToken.CreatedAt.After(since) {
+			count++
+			if passwordlessToken.CreatedAt.Before(oldest) {
+				oldest = passwordlessToken.CreatedAt
+			}
+		}
+	}
+	return count, oldest
+}
+
+type passwordlessTest struct {
+	service  PasswordlessService
+	tokens   *fakePasswordlessTokenRepository
+	lockout  *fakeLoginLockoutService
+	sessions *fakeSessionIssuer
+	audit    *fakeAuditRecorder
+	webhooks *fakeWebhookService
+	mailer   *mailer.MemoryMailerImpl
+}
+
+func newPasswordlessTest(t *testing.T) *passwordlessTest {
+	DB, err := sql.Open("journal", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { DB.Close() })
+
+	test := &passwordlessTest{
+		tokens:   &fakePasswordlessTokenRepository{},
+		lockout:  &fakeLoginLockoutService{},
+		sessions: &fakeSessionIssuer{},
+		audit:    &fakeAuditRecorder{},
+		webhooks: &fakeWebhookService{},
+		mailer:   mailer.NewMemoryMailer(),
+	}
+	userRepository := &fakeUserRepository{users: []domain.User{
+		{ID: 1, Username: "arthur", Email: "arthur@example.com"},
+	}}
+	test.service = NewPasswordlessService(userRepository, test.tokens, DB, validator.New(), test.lockout, test.sessions, nil, test.audit, test.webhooks, test.mailer, PasswordlessConfig{
+		BaseURL:         "https://example.com",
+		LinkTTL:         15 * time.Minute,
+		CodeTTL:         10 * time.Minute,
+		MaxCodeAttempts: 3,
+		RateLimit:       3,
+		RateWindow:      time.Hour,
+	})
+	return test
+}
+
+var (
+	loginLinkPattern = regexp.MustCompile(`\?token=(\S+)`)
+	loginCodePattern = regexp.MustCompile(`code is (\d{6})`)
+)
+
+// request asks for a login email and returns the link token or code from
+// it. The email is sent in the background, so it is waited for.
+func (test *passwordlessTest) request(t *testing.T, method string) string {
+	t.Helper()
+	test.mailer.Reset()
+	test.service.RequestLogin(context.Background(), web.PasswordlessLoginRequest{Email: "arthur@example.com", Method: method})
+
+	pattern := loginLinkPattern
+	if method == domain.PasswordlessMethodCode {
+		pattern = loginCodePattern
+	}
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if message, ok := test.mailer.LastTo("arthur@example.com"); ok {
+			match := pattern.FindStringSubmatch(message.Body)
+			if match == nil {
+				t.Fatalf("email body %q has no %s", message.Body, method)
+			}
+			return match[1]
+		}
+	}
+	t.Fatal("no login email was sent")
+	return ""
+}
+
+func (test *passwordlessTest) verify(request web.PasswordlessVerifyRequest) web.UserLoginResponse {
+	return test.service.VerifyLogin(context.Background(), request)
+}
+
+func TestPasswordlessLinkLogin(t *testing.T) {
+	test := newPasswordlessTest(t)
+	token := test.request(t, domain.PasswordlessMethodLink)
+
+	response := test.verify(web.PasswordlessVerifyRequest{Token: token})
+	if response.AccessToken != "access-token" {
+		t.Errorf("VerifyLogin = %+v, want the issued session", response)
+	}
+	if len(test.sessions.sessions) != 1 || test.sessions.sessions[0].Methods[0] != web.AmrEmail {
+		t.Errorf("sessions issued = %+v, want one email session", test.sessions.sessions)
+	}
+
+	expectPanic[exception.UnauthorizedError](t, func() {
+		test.verify(web.PasswordlessVerifyRequest{Token: token})
+	})
+	if len(test.sessions.sessions) != 1 {
+		t.Errorf("a used link issued another session")
+	}
+}
+
+func TestPasswordlessCodeLogin(t *testing.T) {
+	test := newPasswordlessTest(t)
+	code := test.request(t, domain.PasswordlessMethodCode)
+
+	response := test.verify(web.PasswordlessVerifyRequest{Email: "arthur@example.com", Code: code})
+	if response.AccessToken != "access-token" {
+		t.Errorf("VerifyLogin = %+v, want the issued session", response)
+	}
+	if test.lockout.successes != 1 {
+		t.Errorf("lockout recorded %d successes, want 1", test.lockout.successes)
+	}
+
+	expectPanic[exception.UnauthorizedError](t, func() {
+		test.verify(web.PasswordlessVerifyRequest{Email: "arthur@example.com", Code: code})
+	})
+}
+
+func TestPasswordlessNewRequestInvalidatesOlderLink(t *testing.T) {
+	test := newPasswordlessTest(t)
+	first := test.request(t, domain.PasswordlessMethodLink)
+	second := test.request(t, domain.PasswordlessMethodLink)
+
+	expectPanic[exception.UnauthorizedError](t, func() {
+		test.verify(web.PasswordlessVerifyRequest{Token: first})
+	})
+	test.verify(web.PasswordlessVerifyRequest{Token: second})
+}
+
+func TestPasswordlessRejectsExpiredTokens(t *testing.T) {
+	test := newPasswordlessTest(t)
+	token := test.request(t, domain.PasswordlessMethodLink)
+	code := test.request(t, domain.PasswordlessMethodCode)
+	for i := range test.tokens.tokens {
+		test.tokens.tokens[i].ExpiresAt = time.Now().Add(-time.Second)
+	}
+
+	expectPanic[exception.UnauthorizedError](t, func() {
+		test.verify(web.PasswordlessVerifyRequest{Token: token})
+	})
+	expectPanic[exception.UnauthorizedError](t, func() {
+		test.verify(web.PasswordlessVerifyRequest{Email: "arthur@example.com", Code: code})
+	})
+	if len(test.sessions.sessions) != 0 {
+		t.Errorf("sessions issued = %+v, want none", test.sessions.sessions)
+	}
+	if len(test.audit.events) != 2 || len(test.webhooks.events) != 2 {
+		t.Errorf("recorded %d audit and %d webhook events, want 2 failed logins each", len(test.audit.events), len(test.webhooks.events))
+	}
+}
+
+func TestPasswordlessLimitsCodeAttempts(t *testing.T) {
+	test := newPasswordlessTest(t)
+	code := test.request(t, domain.PasswordlessMethodCode)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	for i := 0; i < 3; i++ {
+		expectPanic[exception.UnauthorizedError](t, func() {
+			test.verify(web.PasswordlessVerifyRequest{Email: "arthur@example.com", Code: wrong})
+		})
+	}
+	if test.lockout.failures != 3 {
+		t.Errorf("lockout recorded %d failures, want 3", test.lockout.failures)
+	}
+	if event := test.audit.events[0]; event.UserID != 1 || event.Metadata["reason"] != "invalid_code" {
+		t.Errorf("audit event = %+v, want a failed code login of user 1", event)
+	}
+
+	// After PASSWORDLESS_MAX_CODE_ATTEMPTS the code is burned.
+	expectPanic[exception.UnauthorizedError](t, func() {
+		test.verify(web.PasswordlessVerifyRequest{Email: "arthur@example.com", Code: code})
+	})
+	if len(test.sessions.sessions) != 0 {
+		t.Errorf("sessions issued = %+v, want none", test.sessions.sessions)
+	}
+}
+
+func TestPasswordlessAcceptsCodeAfterFewerFailedAttempts(t *testing.T) {
+	test := newPasswordlessTest(t)
+	code := test.request(t, domain.PasswordlessMethodCode)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	for i := 0; i < 2; i++ {
+		expectPanic[exception.UnauthorizedError](t, func() {
+			test.verify(web.PasswordlessVerifyRequest{Email: "arthur@example.com", Code: wrong})
+		})
+	}
+	test.verify(web.PasswordlessVerifyRequest{Email: "arthur@example.com", Code: code})
+	if test.tokens.tokens[0].Attempts != 2 {
+		t.Errorf("attempts = %d, want 2", test.tokens.tokens[0].Attempts)
+	}
+}
+
+func TestPasswordlessRateLimitsRequestsPerAddress(t *testing.T) {
+	test := newPasswordlessTest(t)
+	for i := 0; i < 3; i++ {
+		test.request(t, domain.PasswordlessMethodLink)
+	}
+
+	err := expectPanic[exception.TooManyRequestsError](t, func() {
+		test.service.RequestLogin(context.Background(), web.PasswordlessLoginRequest{Email: "ARTHUR@example.com", Method: domain.PasswordlessMethodCode})
+	})
+	if err.RetryAfter <= 0 || err.RetryAfter > time.Hour {
+		t.Errorf("RetryAfter = %s, want up to the rate window", err.RetryAfter)
+	}
+
+	// Other addresses, including unknown ones, have their own quota.
+	test.service.RequestLogin(context.Background(), web.PasswordlessLoginRequest{Email: "ford@example.com", Method: domain.PasswordlessMethodLink})
+}
+
+func TestPasswordlessSendsNoEmailToUnknownAddresses(t *testing.T) {
+	test := newPasswordlessTest(t)
+	test.service.RequestLogin(context.Background(), web.PasswordlessLoginRequest{Email: "ford@example.com", Method: domain.PasswordlessMethodLink})
+
+	if len(test.tokens.tokens) != 1 || test.tokens.tokens[0].UserID != nil {
+		t.Errorf("tokens = %+v, want one token without a user", test.tokens.tokens)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if messages := test.mailer.Messages(); len(messages) != 0 {
+		t.Errorf("sent %+v, want no email", messages)
+	}
+}
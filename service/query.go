@@ -0,0 +1,28 @@
+package service
+
+import (
+	"fmt"
+	"golang_jwt/exception"
+	"golang_jwt/model/web"
+	"strconv"
+)
+
+// parseQueryNumber parses an optional numeric filter of a list endpoint;
+// zero means the filter is not set. The validator only checks for digits,
+// so a value too large for bitSize is rejected here instead of the filter
+// being dropped silently.
+func parseQueryNumber(field string, value string, bitSize int) int64 {
+	if value == "" {
+		return 0
+	}
+
+	number, err := strconv.ParseInt(value, 10, bitSize)
+	if err != nil {
+		panic(exception.NewValidationError([]web.FieldError{{
+			Field:   field,
+			Rule:    "range",
+			Message: fmt.Sprintf("%s must be a number between 0 and %d", field, int64(1)<<(bitSize-1)-1),
+		}}))
+	}
+	return number
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"golang_jwt/model/domain"
+	"golang_jwt/model/web"
+	"time"
+)
+
+const (
+	SessionLimitEvictOldest = "evict_oldest"
+	SessionLimitReject      = "reject"
+)
+
+// Actions taken when a renewal looks different from the login.
+const (
+	AnomalyPolicyOff            = "off"
+	AnomalyPolicyFlag           = "flag"
+	AnomalyPolicyReject         = "reject"
+	AnomalyPolicyReauthenticate = "reauthenticate"
+)
+
+// Heuristics that decide when a renewal comes from a different network.
+const (
+	IpChangeHeuristicOff     = "off"
+	IpChangeHeuristicAddress = "address"
+	IpChangeHeuristicSubnet  = "subnet"
+	IpChangeHeuristicAsn     = "asn"
+)
+
+// SessionConfig bounds how long a session can be renewed. A session expires
+// when it is not renewed within IdleTimeout, and in any case AbsoluteLifetime
+// after login.
+//
+// MaxActive caps the active sessions per user (0 means unlimited) and can be
+// overridden per role in MaxActiveByRole. LimitPolicy decides what a login
+// beyond the cap does: evict the oldest session or be rejected.
+//
+// FingerprintPolicy (off, flag or reject) applies when the client
+// fingerprint differs from the one recorded at login. IpChangePolicy (flag
+// or reauthenticate) applies when IpChangeHeuristic considers the address
+// to belong to a different network than the previous renewal.
+type SessionConfig struct {
+	IdleTimeout       time.Duration
+	AbsoluteLifetime  time.Duration
+	MaxActive         int
+	MaxActiveByRole   map[string]int
+	LimitPolicy       string
+	FingerprintPolicy string
+	IpChangeHeuristic string
+	IpChangePolicy    string
+}
+
+func (config SessionConfig) maxActiveSessions(role string) int {
+	if maxActive, ok := config.MaxActiveByRole[role]; ok {
+		return maxActive
+	}
+	return config.MaxActive
+}
+
+// SessionIssuer is the single path that turns an authenticated user into a
+// session and token pair, whichever way the user proved their identity.
+type SessionIssuer interface {
+	IssueSession(ctx context.Context, tx *sql.Tx, user domain.User, authentication Authentication) web.UserLoginResponse
+	// IssueMfaChallenge is used instead of IssueSession when the first factor
+	// succeeded but the account requires a second one. The first factor is
+	// kept in the challenge token.
+	IssueMfaChallenge(user domain.User, authentication Authentication) web.UserLoginResponse
+	// ExtendSession enforces the idle and absolute lifetimes on renewal and
+	// slides the idle deadline forward. It returns the access token lifetime,
+	// which never reaches past the absolute deadline.
+	// Renewals from a changed client or network are flagged or refused
+	// according to the configured policies.
+	ExtendSession(ctx context.Context, userId int, session domain.Session) (domain.Session, time.Duration)
+	// ReissueAccessToken returns a new access token for an active session,
+	// e.g. with the fresh authentication time of a re-authentication. Like
+	// on renewal, it never outlives the session's absolute deadline.
+	ReissueAccessToken(user domain.User, session domain.Session, authentication Authentication, confirmation *web.Confirmation) (string, *web.UserClaims)
+}
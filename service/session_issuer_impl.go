@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"golang_jwt/asn"
+	"golang_jwt/audit"
+	"golang_jwt/dpop"
+	"golang_jwt/exception"
+	"golang_jwt/helper"
+	"golang_jwt/model/domain"
+	"golang_jwt/model/web"
+	"golang_jwt/repository"
+	"golang_jwt/token"
+	"golang_jwt/webhook"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SessionIssuerImpl struct {
+	SessionStore   repository.SessionStore
+	UserToken      token.UserToken
+	AuditRecorder  audit.Recorder
+	WebhookService WebhookService
+	AsnResolver    asn.Resolver
+	Config         SessionConfig
+}
+
+func NewSessionIssuer(sessionStore repository.SessionStore, userToken token.UserToken, auditRecorder audit.Recorder, webhookService WebhookService, asnResolver asn.Resolver, config SessionConfig) SessionIssuer {
+	return &SessionIssuerImpl{
+		SessionStore:   sessionStore,
+		UserToken:      userToken,
+		AuditRecorder:  auditRecorder,
+		WebhookService: webhookService,
+		AsnResolver:    asnResolver,
+		Config:         config,
+	}
+}
+
+const accessTokenTTL = 15 * time.Minute
+
+func (issuer *SessionIssuerImpl) IssueSession(ctx context.Context, tx *sql.Tx, user domain.User, authentication Authentication) web.UserLoginResponse {
+	issuer.enforceSessionLimit(ctx, tx, user)
+
+	userClaims := web.UserClaims{
+		ID:           user.ID,
+		Username:     user.Username,
+		Email:        user.Email,
+		Role:         user.Role,
+		TokenVersion: user.TokenVersion,
+	}
+	authentication.applyTo(&userClaims)
+	if thumbprint := dpop.ThumbprintFromContext(ctx); thumbprint != "" {
+		userClaims.Confirmation = &web.Confirmation{JKT: thumbprint}
+	}
+
+	userClaims.TokenType = web.TokenTypeRefresh
+	refreshToken, refreshClaims, err := issuer.UserToken.GenerateToken(userClaims, issuer.Config.AbsoluteLifetime)
+	helper.ErrorConditionCheck(err)
+	absoluteExpiresAt := refreshClaims.RegisteredClaims.ExpiresAt.Time
+
+	// The refresh token's jti is the session id, so it is created first and
+	// the access token can point at it.
+	userClaims.TokenType = web.TokenTypeAccess
+	userClaims.SessionID = refreshClaims.RegisteredClaims.ID
+	accessToken, accessClaims, err := issuer.UserToken.GenerateToken(userClaims, issuer.accessTokenTTL(absoluteExpiresAt))
+	helper.ErrorConditionCheck(err)
+
+	clientInfo := helper.ClientInfoFromContext(ctx)
+	session := domain.Session{
+		ID:                  refreshClaims.RegisteredClaims.ID,
+		User_Email:          user.Email,
+		Refresh_Token:       refreshToken,
+		Is_Revoked:          false,
+		Expires_At:          issuer.idleExpiresAt(absoluteExpiresAt),
+		Absolute_Expires_At: absoluteExpiresAt,
+		Ip_Address:          clientInfo.IPAddress,
+		User_Agent:          clientInfo.UserAgent,
+		Device_Label:        helper.DeviceLabel(clientInfo.UserAgent),
+		Fingerprint:         clientInfo.Fingerprint(),
+	}
+	session = issuer.SessionStore.Create(ctx, session)
+	recordAudit(ctx, issuer.AuditRecorder, audit.Event{
+		Type:      audit.EventLogin,
+		ActorID:   user.ID,
+		UserID:    user.ID,
+		SessionID: session.ID,
+		Metadata: map[string]string{
+			"methods": strings.Join(authentication.Methods, ","),
+			"acr":     authentication.Acr(),
+		},
+	})
+
+	return helper.ToUserLoginResponse(accessToken, accessClaims, refreshToken, session, user)
+}
+
+func (issuer *SessionIssuerImpl) IssueMfaChallenge(user domain.User, authentication Authentication) web.UserLoginResponse {
+	userClaims := web.UserClaims{
+		ID:           user.ID,
+		Username:     user.Username,
+		Email:        user.Email,
+		Role:         user.Role,
+		TokenType:    web.TokenTypeMfa,
+		TokenVersion: user.TokenVersion,
+	}
+	authentication.applyTo(&userClaims)
+
+	mfaToken, mfaClaims, err := issuer.UserToken.GenerateToken(userClaims, 5*time.Minute)
+	helper.ErrorConditionCheck(err)
+
+	return helper.ToMfaChallengeResponse(mfaToken, mfaClaims, []string{"totp", "recovery_code"})
+}
+
+func (issuer *SessionIssuerImpl) ExtendSession(ctx context.Context, userId int, session domain.Session) (domain.Session, time.Duration) {
+	now := time.Now()
+	if !now.Before(session.Expires_At) || !now.Before(session.Absolute_Expires_At) {
+		panic(exception.NewUnauthorizedError("session expired"))
+	}
+
+	clientInfo := helper.ClientInfoFromContext(ctx)
+	issuer.checkFingerprint(ctx, userId, session, clientInfo)
+	issuer.checkIpChange(ctx, userId, session, clientInfo)
+
+	session.Ip_Address = clientInfo.IPAddress
+	session.User_Agent = clientInfo.UserAgent
+	session.Device_Label = helper.DeviceLabel(clientInfo.UserAgent)
+	if session.Fingerprint != "" {
+		session.Fingerprint = clientInfo.Fingerprint()
+	}
+	session.Expires_At = issuer.idleExpiresAt(session.Absolute_Expires_At)
+	issuer.SessionStore.UpdateActivity(ctx, session)
+
+	return session, issuer.accessTokenTTL(session.Absolute_Expires_At)
+}
+
+func (issuer *SessionIssuerImpl) ReissueAccessToken(user domain.User, session domain.Session, authentication Authentication, confirmation *web.Confirmation) (string, *web.UserClaims) {
+	now := time.Now()
+	if session.Is_Revoked || session.User_Email != user.Email {
+		panic(exception.NewUnauthorizedError("session is revoked"))
+	}
+	if !now.Before(session.Expires_At) || !now.Before(session.Absolute_Expires_At) {
+		panic(exception.NewUnauthorizedError("session expired"))
+	}
+
+	userClaims := web.UserClaims{
+		ID:           user.ID,
+		Username:     user.Username,
+		Email:        user.Email,
+		Role:         user.Role,
+		TokenType:    web.TokenTypeAccess,
+		SessionID:    session.ID,
+		TokenVersion: user.TokenVersion,
+		Confirmation: confirmation,
+	}
+	authentication.applyTo(&userClaims)
+
+	accessToken, accessClaims, err := issuer.UserToken.GenerateToken(userClaims, issuer.accessTokenTTL(session.Absolute_Expires_At))
+	helper.ErrorConditionCheck(err)
+	return accessToken, accessClaims
+}
+
+// enforceSessionLimit makes room for the session about to be created. Under
+// the reject policy the login fails instead, and nothing is revoked.
+func (issuer *SessionIssuerImpl) enforceSessionLimit(ctx context.Context, tx *sql.Tx, user domain.User) {
+	maxActive := issuer.Config.maxActiveSessions(user.Role)
+	if maxActive <= 0 {
+		return
+	}
+
+	sessions := issuer.SessionStore.FindByEmail(ctx, user.Email)
+	if len(sessions) < maxActive {
+		return
+	}
+
+	if issuer.Config.LimitPolicy == SessionLimitReject {
+		recordAudit(ctx, issuer.AuditRecorder, audit.Event{
+			Type:    audit.EventSessionLimitReached,
+			Outcome: audit.OutcomeFailure,
+			ActorID: user.ID,
+			UserID:  user.ID,
+			Metadata: map[string]string{
+				"active_sessions": strconv.Itoa(len(sessions)),
+			},
+		})
+		panic(exception.NewForbiddenError("maximum number of active sessions reached"))
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Created_At.Before(sessions[j].Created_At)
+	})
+	for _, session := range sessions[:len(sessions)-maxActive+1] {
+		issuer.SessionStore.Revoke(ctx, session.ID)
+		recordAudit(ctx, issuer.AuditRecorder, audit.Event{
+			Type:      audit.EventSessionEvicted,
+			ActorID:   user.ID,
+			UserID:    user.ID,
+			SessionID: session.ID,
+		})
+		issuer.WebhookService.Publish(ctx, tx, webhook.EventSessionRevoked, map[string]interface{}{
+			"user_id":    user.ID,
+			"session_id": session.ID,
+			"reason":     "session_limit",
+		})
+	}
+}
+
+func (issuer *SessionIssuerImpl) idleExpiresAt(absoluteExpiresAt time.Time) time.Time {
+	expiresAt := time.Now().Add(issuer.Config.IdleTimeout)
+	if expiresAt.After(absoluteExpiresAt) {
+		return absoluteExpiresAt
+	}
+	return expiresAt
+}
+
+func (issuer *SessionIssuerImpl) accessTokenTTL(absoluteExpiresAt time.Time) time.Duration {
+	return min(accessTokenTTL, time.Until(absoluteExpiresAt))
+}
+
+// checkFingerprint compares the client with the one of the previous renewal,
+// which ExtendSession stores, so a flagged change is only recorded once.
+// Sessions from before fingerprints were recorded are not checked.
+func (issuer *SessionIssuerImpl) checkFingerprint(ctx context.Context, userId int, session domain.Session, clientInfo helper.ClientInfo) {
+	policy := issuer.Config.FingerprintPolicy
+	if policy == AnomalyPolicyOff || session.Fingerprint == "" || session.Fingerprint == clientInfo.Fingerprint() {
+		return
+	}
+
+	recordAudit(ctx, issuer.AuditRecorder, audit.Event{
+		Type:      audit.EventSessionFingerprintChanged,
+		Outcome:   anomalyOutcome(policy == AnomalyPolicyReject),
+		UserID:    userId,
+		SessionID: session.ID,
+		Metadata: map[string]string{
+			"action": policy,
+		},
+	})
+	if policy == AnomalyPolicyReject {
+		issuer.revokeAnomalousSession(ctx, userId, session, "fingerprint_changed")
+		panic(exception.NewUnauthorizedError("session was created on a different device"))
+	}
+}
+
+// checkIpChange compares the address with the one of the previous renewal.
+// Under the reauthenticate policy the session is revoked and the client has
+// to log in again to get new tokens.
+func (issuer *SessionIssuerImpl) checkIpChange(ctx context.Context, userId int, session domain.Session, clientInfo helper.ClientInfo) {
+	heuristic := issuer.Config.IpChangeHeuristic
+	if heuristic == IpChangeHeuristicOff || session.Ip_Address == "" || session.Ip_Address == clientInfo.IPAddress {
+		return
+	}
+
+	previousIp := net.ParseIP(session.Ip_Address)
+	currentIp := net.ParseIP(clientInfo.IPAddress)
+	metadata := map[string]string{
+		"previous_ip": session.Ip_Address,
+		"ip":          clientInfo.IPAddress,
+		"heuristic":   heuristic,
+		"action":      issuer.Config.IpChangePolicy,
+	}
+
+	changed := true
+	switch heuristic {
+	case IpChangeHeuristicSubnet:
+		changed = !sameSubnet(previousIp, currentIp)
+	case IpChangeHeuristicAsn:
+		changed = issuer.asnChanged(previousIp, currentIp, metadata)
+	}
+	if !changed {
+		return
+	}
+
+	recordAudit(ctx, issuer.AuditRecorder, audit.Event{
+		Type:      audit.EventSessionIpChanged,
+		Outcome:   anomalyOutcome(issuer.Config.IpChangePolicy == AnomalyPolicyReauthenticate),
+		UserID:    userId,
+		SessionID: session.ID,
+		Metadata:  metadata,
+	})
+	if issuer.Config.IpChangePolicy == AnomalyPolicyReauthenticate {
+		issuer.revokeAnomalousSession(ctx, userId, session, "ip_changed")
+		panic(exception.NewUnauthorizedError("re-authentication required"))
+	}
+}
+
+// revokeAnomalousSession ends a session whose renewal is refused. The
+// webhook is published in its own transaction, as the renewal's is rolled
+// back.
+func (issuer *SessionIssuerImpl) revokeAnomalousSession(ctx context.Context, userId int, session domain.Session, reason string) {
+	issuer.SessionStore.Revoke(ctx, session.ID)
+	issuer.WebhookService.PublishSeparately(ctx, webhook.EventSessionRevoked, map[string]interface{}{
+		"user_id":    userId,
+		"session_id": session.ID,
+		"reason":     reason,
+	})
+}
+
+// anomalyOutcome marks anomaly events that end the renewal as failures.
+func anomalyOutcome(refused bool) audit.Outcome {
+	if refused {
+		return audit.OutcomeFailure
+	}
+	return audit.OutcomeSuccess
+}
+
+// asnChanged falls back to the subnet comparison when either address cannot
+// be resolved, including when no ASN database is configured.
+func (issuer *SessionIssuerImpl) asnChanged(previousIp net.IP, currentIp net.IP, metadata map[string]string) bool {
+	if issuer.AsnResolver == nil {
+		return !sameSubnet(previousIp, currentIp)
+	}
+
+	previousAsn, previousOk := issuer.AsnResolver.Lookup(previousIp)
+	currentAsn, currentOk := issuer.AsnResolver.Lookup(currentIp)
+	if !previousOk || !currentOk {
+		return !sameSubnet(previousIp, currentIp)
+	}
+
+	metadata["previous_asn"] = strconv.FormatUint(uint64(previousAsn), 10)
+	metadata["asn"] = strconv.FormatUint(uint64(currentAsn), 10)
+	return previousAsn != currentAsn
+}
+
+// sameSubnet treats addresses in the same /24 (IPv4) or /48 (IPv6) as one
+// network, so renumbering within a home or office connection is not flagged.
+func sameSubnet(previousIp net.IP, currentIp net.IP) bool {
+	if previousIp == nil || currentIp == nil {
+		return false
+	}
+
+	if previousIp.To4() != nil && currentIp.To4() != nil {
+		mask := net.CIDRMask(24, 32)
+		return previousIp.To4().Mask(mask).Equal(currentIp.To4().Mask(mask))
+	}
+	if previousIp.To4() == nil && currentIp.To4() == nil {
+		mask := net.CIDRMask(48, 128)
+		return previousIp.Mask(mask).Equal(currentIp.Mask(mask))
+	}
+	return false
+}
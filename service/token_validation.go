@@ -0,0 +1,22 @@
+package service
+
+import (
+	"golang_jwt/model/web"
+	"golang_jwt/token"
+)
+
+// validateTokenSafely returns nil instead of panicking when the token is
+// malformed, expired or signed with another key.
+func validateTokenSafely(userToken token.UserToken, tokenString string) (claims *web.UserClaims) {
+	defer func() {
+		if recover() != nil {
+			claims = nil
+		}
+	}()
+
+	claims, err := userToken.ValidateToken(tokenString)
+	if err != nil {
+		return nil
+	}
+	return claims
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"golang_jwt/model/web"
+	"time"
+)
+
+// TrustedDeviceService lets a device that completed MFA skip the second
+// factor on later logins. The methods taking a tx run inside the caller's
+// login transaction.
+type TrustedDeviceService interface {
+	Trust(ctx context.Context, tx *sql.Tx, userId int) (string, time.Time)
+	IsTrusted(ctx context.Context, tx *sql.Tx, userId int, deviceToken string) bool
+	RevokeAll(ctx context.Context, tx *sql.Tx, userId int)
+	FindAll(ctx context.Context, userId int) []web.TrustedDeviceResponse
+	Revoke(ctx context.Context, userId int, deviceId string)
+}
+
+type TrustedDeviceConfig struct {
+	TTL time.Duration
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"golang_jwt/exception"
+	"golang_jwt/helper"
+	"golang_jwt/model/domain"
+	"golang_jwt/model/web"
+	"golang_jwt/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TrustedDeviceServiceImpl struct {
+	TrustedDeviceRepository repository.TrustedDeviceRepository
+	DB                      *sql.DB
+	Config                  TrustedDeviceConfig
+}
+
+func NewTrustedDeviceService(trustedDeviceRepository repository.TrustedDeviceRepository, DB *sql.DB, config TrustedDeviceConfig) TrustedDeviceService {
+	return &TrustedDeviceServiceImpl{
+		TrustedDeviceRepository: trustedDeviceRepository,
+		DB:                      DB,
+		Config:                  config,
+	}
+}
+
+// Trust returns the device token once; only its hash is stored, so a
+// database leak does not let anyone skip MFA.
+func (service *TrustedDeviceServiceImpl) Trust(ctx context.Context, tx *sql.Tx, userId int) (string, time.Time) {
+	clientInfo := helper.ClientInfoFromContext(ctx)
+	deviceToken := helper.GenerateRandomToken(32)
+
+	trustedDevice := service.TrustedDeviceRepository.Save(ctx, tx, domain.TrustedDevice{
+		ID:        uuid.New().String(),
+		UserID:    userId,
+		TokenHash: helper.HashToken(deviceToken),
+		Label:     helper.DeviceLabel(clientInfo.UserAgent),
+		IPAddress: clientInfo.IPAddress,
+		UserAgent: clientInfo.UserAgent,
+		ExpiresAt: time.Now().Add(service.Config.TTL),
+	})
+
+	return deviceToken, trustedDevice.ExpiresAt
+}
+
+func (service *TrustedDeviceServiceImpl) IsTrusted(ctx context.Context, tx *sql.Tx, userId int, deviceToken string) bool {
+	if deviceToken == "" {
+		return false
+	}
+
+	trustedDevice, err := service.TrustedDeviceRepository.FindByTokenHash(ctx, tx, helper.HashToken(deviceToken))
+	if err != nil || trustedDevice.UserID != userId || time.Now().After(trustedDevice.ExpiresAt) {
+		return false
+	}
+
+	service.TrustedDeviceRepository.UpdateLastUsed(ctx, tx, trustedDevice.ID)
+	return true
+}
+
+func (service *TrustedDeviceServiceImpl) RevokeAll(ctx context.Context, tx *sql.Tx, userId int) {
+	service.TrustedDeviceRepository.DeleteByUserId(ctx, tx, userId)
+}
+
+func (service *TrustedDeviceServiceImpl) FindAll(ctx context.Context, userId int) []web.TrustedDeviceResponse {
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	trustedDevices := service.TrustedDeviceRepository.FindByUserId(ctx, tx, userId)
+	return helper.ToTrustedDeviceResponses(trustedDevices)
+}
+
+func (service *TrustedDeviceServiceImpl) Revoke(ctx context.Context, userId int, deviceId string) {
+	if uuid.Validate(deviceId) != nil {
+		panic(exception.NewNotFoundError("trusted device not found"))
+	}
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	err = service.TrustedDeviceRepository.Delete(ctx, tx, deviceId, userId)
+	if err != nil {
+		panic(exception.NewNotFoundError(err.Error()))
+	}
+}
@@ -6,11 +6,17 @@ import (
 )
 
 type UserService interface {
-	Register(ctx context.Context, request web.UserCreateRequest) web.UserResponse 
+	Register(ctx context.Context, request web.UserCreateRequest)
 	Login(ctx context.Context, request web.UserLoginRequest) web.UserLoginResponse
-	Logout(ctx context.Context, sessionId string) 
+	Logout(ctx context.Context, claims *web.UserClaims, request web.LogoutRequest)
 	RenewAccessToken(ctx context.Context, request web.RenewAccessTokenRequest) web.RenewAccessTokenResponse
-	RevokeSession(ctx context.Context, sessionId string)
+	RevokeSession(ctx context.Context, claims *web.UserClaims, request web.RevokeSessionRequest)
+	Reauthenticate(ctx context.Context, claims *web.UserClaims, request web.ReauthenticateRequest) web.RenewAccessTokenResponse
+	DeleteAccount(ctx context.Context, userId int)
+	LogoutAll(ctx context.Context, userId int)
+	CurrentTokenVersion(ctx context.Context, userId int) (int, error)
+	FindSessions(ctx context.Context, userId int, currentSessionId string) []web.SessionResponse
+	DeleteSession(ctx context.Context, userId int, sessionId string)
 	FindById(ctx context.Context, userId int) web.UserResponse
 	FindAll(ctx context.Context) []web.UserResponse
 }
@@ -5,39 +5,98 @@ import (
     "database/sql"
     "golang_jwt/model/web"
     "golang_jwt/model/domain"
+	"golang_jwt/audit"
+	"golang_jwt/dpop"
 	"golang_jwt/exception"
+	"golang_jwt/hasher"
 	"golang_jwt/helper"
+	"golang_jwt/mailer"
+	"golang_jwt/policy"
     "golang_jwt/repository"
 	"golang_jwt/token"
+	"golang_jwt/webhook"
     "github.com/go-playground/validator/v10"
-	"time"
 	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
 )
 
 type UserServiceImpl struct {
     UserRepository repository.UserRepository
+	SessionStore repository.SessionStore
     DB *sql.DB
     Validate *validator.Validate
 	UserToken token.UserToken
+	PasswordHasher hasher.PasswordHasher
+	PasswordPolicy policy.PasswordPolicy
+	LoginLockoutService LoginLockoutService
+	Mailer mailer.Mailer
+	SessionIssuer SessionIssuer
+	MfaService MfaService
+	TrustedDeviceService TrustedDeviceService
+	AuditRecorder audit.Recorder
+	WebhookService WebhookService
+
+	dummyHashMutex sync.Mutex
+	dummyHash string
 }
 
-func NewUserService(userRepository repository.UserRepository, DB *sql.DB, Validate *validator.Validate, userToken token.UserToken) UserService {
+func NewUserService(userRepository repository.UserRepository, sessionStore repository.SessionStore, DB *sql.DB, Validate *validator.Validate, userToken token.UserToken, passwordHasher hasher.PasswordHasher, passwordPolicy policy.PasswordPolicy, loginLockoutService LoginLockoutService, mailer mailer.Mailer, sessionIssuer SessionIssuer, mfaService MfaService, trustedDeviceService TrustedDeviceService, auditRecorder audit.Recorder, webhookService WebhookService) UserService {
 	return  &UserServiceImpl{
 		UserRepository: userRepository,
+		SessionStore: sessionStore,
 		DB: DB,
 		Validate: Validate,
 		UserToken: userToken,
+		PasswordHasher: passwordHasher,
+		PasswordPolicy: passwordPolicy,
+		LoginLockoutService: loginLockoutService,
+		Mailer: mailer,
+		SessionIssuer: sessionIssuer,
+		MfaService: mfaService,
+		TrustedDeviceService: trustedDeviceService,
+		AuditRecorder: auditRecorder,
+		WebhookService: webhookService,
 	}
 }
 
-func (service *UserServiceImpl) Register(ctx context.Context, request web.UserCreateRequest) web.UserResponse {
+// Register answers identically whether or not the email is already taken,
+// and always hashes the password so both paths take the same time. The owner
+// of an existing account is told about the attempt by email instead.
+func (service *UserServiceImpl) Register(ctx context.Context, request web.UserCreateRequest) {
 	err := service.Validate.Struct(request)
 	helper.ErrorConditionCheck(err)
+
+	violations, err := service.PasswordPolicy.Validate("password", request.Password, policy.PasswordIdentity{
+		Username: request.Username,
+		Email: request.Email,
+	})
+	helper.ErrorConditionCheck(err)
+	if len(violations) > 0 {
+		panic(exception.NewValidationError(violations))
+	}
+
 	tx, err := service.DB.Begin()
 	helper.ErrorConditionCheck(err)
 	defer helper.CommitOrRollback(tx)
 
-	hashedPassword := helper.HashPassword(request.Password)
+	// The password is hashed before anything is looked up, so a taken
+	// username or email takes as long to answer as a new account.
+	hashedPassword, err := service.PasswordHasher.Hash(ctx, request.Password)
+	hasherErrorCheck(err)
+
+	existing, err := service.UserRepository.FindByUsername(ctx, tx, request.Username)
+	if err == nil {
+		service.recordRegistrationFailure(ctx, "username_taken")
+		service.sendMail(ctx, mailer.Message{
+			To: existing.Email,
+			Subject: "Registration attempt for your account",
+			Body: "Someone tried to create a new account with your username, but it is already registered.\n\nIf this was you, sign in instead or use the forgot password option to reset your password. If it wasn't you, you can ignore this email.",
+		})
+		return
+	}
 
 	user := domain.User{
 		Username: request.Username,
@@ -45,9 +104,34 @@ func (service *UserServiceImpl) Register(ctx context.Context, request web.UserCr
 		Password: hashedPassword,
 	}
 
-	user = service.UserRepository.Register(ctx, tx, user)
+	user, err = service.UserRepository.Register(ctx, tx, user)
+	if errors.Is(err, repository.ErrEmailTaken) {
+		service.recordRegistrationFailure(ctx, "email_taken")
+		service.sendMail(ctx, mailer.Message{
+			To: request.Email,
+			Subject: "Registration attempt for your account",
+			Body: "Someone tried to create a new account with this email address, but it is already registered.\n\nIf this was you, sign in instead or use the forgot password option to reset your password. If it wasn't you, you can ignore this email.",
+		})
+		return
+	}
+	helper.ErrorConditionCheck(err)
 
-	return helper.ToUserResponse(user)
+	recordAudit(ctx, service.AuditRecorder, audit.Event{
+		Type: audit.EventUserRegistered,
+		ActorID: user.ID,
+		UserID: user.ID,
+	})
+	service.WebhookService.Publish(ctx, tx, webhook.EventUserRegistered, map[string]interface{}{
+		"user_id": user.ID,
+		"username": user.Username,
+		"email": user.Email,
+	})
+
+	service.sendMail(ctx, mailer.Message{
+		To: user.Email,
+		Subject: "Welcome",
+		Body: fmt.Sprintf("Hi %s,\n\nYour account has been created. You can now sign in with your username or email.", user.Username),
+	})
 }
 
 func (service *UserServiceImpl) Login(ctx context.Context, request web.UserLoginRequest) web.UserLoginResponse {
@@ -58,37 +142,154 @@ func (service *UserServiceImpl) Login(ctx context.Context, request web.UserLogin
 	helper.ErrorConditionCheck(err)
 	defer helper.CommitOrRollback(tx)
 
-	user, err := service.UserRepository.FindByEmail(ctx, tx, request.Email)
-	helper.ErrorConditionCheck(err)
+	identifier := request.Identifier
+	if identifier == "" {
+		identifier = request.Email
+	}
 
-	helper.VerifyPassword(user.Password, request.Password)
-	
-	accessToken, accessClaims, err := service.UserToken.GenerateToken(user.ID, user.Username, user.Email, 15*time.Minute)
-	helper.ErrorConditionCheck(err)
+	user, err := findUserByIdentifier(ctx, tx, service.UserRepository, identifier)
+	accountKey := AccountKeyForLogin(identifier)
+	if err == nil {
+		accountKey = AccountKeyForUser(user.ID)
+	}
+
+	service.LoginLockoutService.CheckAllowed(ctx, accountKey)
+	if err != nil {
+		_, err = service.PasswordHasher.Verify(ctx, service.getDummyHash(ctx), request.Password)
+		hasherErrorCheck(err)
+		service.LoginLockoutService.RecordFailure(ctx, nil, accountKey)
+		recordLoginFailure(ctx, service.AuditRecorder, service.WebhookService, web.AmrPassword, 0, "unknown_account")
+		panic(exception.NewUnauthorizedError("invalid credentials"))
+	}
 
-    refreshToken, refreshClaims, err := service.UserToken.GenerateToken(user.ID, user.Username, user.Email, 24*time.Hour)
-    helper.ErrorConditionCheck(err)
+	valid, err := service.PasswordHasher.Verify(ctx, user.Password, request.Password)
+	hasherErrorCheck(err)
+	if !valid {
+		service.LoginLockoutService.RecordFailure(ctx, &user, accountKey)
+		recordLoginFailure(ctx, service.AuditRecorder, service.WebhookService, web.AmrPassword, user.ID, "invalid_password")
+		panic(exception.NewUnauthorizedError("invalid credentials"))
+	}
+	service.LoginLockoutService.RecordSuccess(ctx, accountKey)
 
-	session := domain.Session{
-		ID: refreshClaims.RegisteredClaims.ID,
-		User_Email: user.Email,
-		Refresh_Token: refreshToken,
-		Is_Revoked: false,
-		Expires_At: refreshClaims.RegisteredClaims.ExpiresAt.Time,
+	if service.PasswordHasher.NeedsRehash(user.Password) {
+		service.rehashPassword(ctx, tx, user.ID, request.Password)
+	}
+
+	if user.TotpEnabled && !service.TrustedDeviceService.IsTrusted(ctx, tx, user.ID, request.DeviceToken) {
+		return service.SessionIssuer.IssueMfaChallenge(user, NewAuthentication(web.AmrPassword))
 	}
-	session = service.UserRepository.CreateSession(ctx, tx, session)
-	helper.ErrorConditionCheck(err)
 
-	return helper.ToUserLoginResponse(accessToken, accessClaims, refreshToken, session, user)
+	return service.SessionIssuer.IssueSession(ctx, tx, user, NewAuthentication(web.AmrPassword))
 }
 
-func (service *UserServiceImpl) Logout(ctx context.Context, sessionId string) {
+// findUserByIdentifier treats anything containing "@" as an email; usernames
+// cannot contain "@" so the two never overlap.
+func findUserByIdentifier(ctx context.Context, tx *sql.Tx, userRepository repository.UserRepository, identifier string) (domain.User, error) {
+	if strings.Contains(identifier, "@") {
+		return userRepository.FindByEmail(ctx, tx, identifier)
+	}
+	return userRepository.FindByUsername(ctx, tx, identifier)
+}
+
+// getDummyHash returns a hash made with the current hasher settings, so a
+// login for an unknown account costs the same as one with a wrong password.
+// A failed attempt, e.g. on a saturated pool, is retried by the next login.
+func (service *UserServiceImpl) getDummyHash(ctx context.Context) string {
+	service.dummyHashMutex.Lock()
+	defer service.dummyHashMutex.Unlock()
+
+	if service.dummyHash == "" {
+		hashedPassword, err := service.PasswordHasher.Hash(ctx, helper.GenerateRandomToken(16))
+		hasherErrorCheck(err)
+		service.dummyHash = hashedPassword
+	}
+	return service.dummyHash
+}
+
+func (service *UserServiceImpl) sendMail(ctx context.Context, message mailer.Message) {
+	err := service.Mailer.Send(ctx, message)
+	if err != nil {
+		log.Printf("Error sending email: %v", err)
+	}
+}
+
+// rehashPassword upgrades a hash produced with an outdated algorithm or
+// parameters. A failure here must not block an otherwise valid login.
+func (service *UserServiceImpl) rehashPassword(ctx context.Context, tx *sql.Tx, userId int, password string) {
+	hashedPassword, err := service.PasswordHasher.Hash(ctx, password)
+	if err != nil {
+		log.Printf("Error rehashing password for user %d: %v", userId, err)
+		return
+	}
+
+	service.UserRepository.UpdatePassword(ctx, tx, userId, hashedPassword)
+}
+
+// The audit events below are written outside tx by the recorder, so they
+// are kept when the surrounding transaction is rolled back. Webhook events
+// are published in tx, so they are only sent when the change is committed.
+
+func (service *UserServiceImpl) recordRegistrationFailure(ctx context.Context, reason string) {
+	recordAudit(ctx, service.AuditRecorder, audit.Event{
+		Type: audit.EventUserRegistered,
+		Outcome: audit.OutcomeFailure,
+		Metadata: map[string]string{
+			"reason": reason,
+		},
+	})
+}
+
+func (service *UserServiceImpl) recordReauthenticationFailure(ctx context.Context, userId int, reason string) {
+	recordAudit(ctx, service.AuditRecorder, audit.Event{
+		Type: audit.EventReauthentication,
+		Outcome: audit.OutcomeFailure,
+		UserID: userId,
+		Metadata: map[string]string{
+			"reason": reason,
+		},
+	})
+}
+
+func (service *UserServiceImpl) recordSessionRevoked(ctx context.Context, tx *sql.Tx, userId int, sessionId string, reason string) {
+	recordAudit(ctx, service.AuditRecorder, audit.Event{
+		Type: audit.EventSessionRevoked,
+		UserID: userId,
+		SessionID: sessionId,
+		Metadata: map[string]string{
+			"reason": reason,
+		},
+	})
+	service.WebhookService.Publish(ctx, tx, webhook.EventSessionRevoked, map[string]interface{}{
+		"user_id": userId,
+		"session_id": sessionId,
+		"reason": reason,
+	})
+}
+
+// rejectRenewal records a refused refresh and then refuses it with err.
+func (service *UserServiceImpl) rejectRenewal(ctx context.Context, userId int, sessionId string, reason string, err interface{}) {
+	recordAudit(ctx, service.AuditRecorder, audit.Event{
+		Type: audit.EventSessionRenewed,
+		Outcome: audit.OutcomeFailure,
+		UserID: userId,
+		SessionID: sessionId,
+		Metadata: map[string]string{
+			"reason": reason,
+		},
+	})
+	panic(err)
+}
+
+// Logout ends the caller's own session. The session comes from the sid
+// claim, or from the refresh token for access tokens issued without one.
+func (service *UserServiceImpl) Logout(ctx context.Context, claims *web.UserClaims, request web.LogoutRequest) {
 	tx, err := service.DB.Begin()
 	helper.ErrorConditionCheck(err)
 	defer helper.CommitOrRollback(tx)
 
-	service.UserRepository.DeleteSession(ctx, tx, sessionId)
-	helper.ErrorConditionCheck(err)
+	session := service.findOwnedSession(ctx, claims, service.currentSessionId(claims, request.RefreshToken))
+	service.SessionStore.Delete(ctx, session.ID)
+	service.recordSessionRevoked(ctx, tx, claims.ID, session.ID, "logout")
 }
 
 func (service *UserServiceImpl) RenewAccessToken(ctx context.Context, request web.RenewAccessTokenRequest) web.RenewAccessTokenResponse {
@@ -102,32 +303,254 @@ func (service *UserServiceImpl) RenewAccessToken(ctx context.Context, request we
 	refreshClaims, err := service.UserToken.ValidateToken(request.RefreshToken)
 	helper.ErrorConditionCheck(err)
 
-	session, err := service.UserRepository.GetSession(ctx, tx, refreshClaims.RegisteredClaims.ID)
+	session, err := service.SessionStore.Get(ctx, refreshClaims.RegisteredClaims.ID)
 	helper.ErrorConditionCheck(err)
 
 	if session.Is_Revoked {
-		helper.ErrorConditionCheck(errors.New("session is revoked"))
+		service.rejectRenewal(ctx, refreshClaims.ID, session.ID, "session_revoked", exception.NewUnauthorizedError("session is revoked"))
 	}
 
-	if session.User_Email != refreshClaims.Email {
-		helper.ErrorConditionCheck(errors.New("refresh token is invalid"))
+	if session.User_Email != refreshClaims.Email || refreshClaims.TokenType != web.TokenTypeRefresh {
+		service.rejectRenewal(ctx, refreshClaims.ID, session.ID, "invalid_token", exception.NewUnauthorizedError("refresh token is invalid"))
 	}
 
-	accessToken, accessClaims, err := service.UserToken.GenerateToken(refreshClaims.ID, refreshClaims.Username, refreshClaims.Email, 15*time.Minute) // ubah di user_token, jangan langsung menerima struct user, tetapi 1 1 saja
+	user, err := service.UserRepository.FindById(ctx, tx, refreshClaims.ID)
 	helper.ErrorConditionCheck(err)
+	if refreshClaims.TokenVersion != user.TokenVersion {
+		service.rejectRenewal(ctx, user.ID, session.ID, "token_version", exception.NewUnauthorizedError("session is revoked"))
+	}
 
+	// A bound refresh token is only accepted with a proof from its key; an
+	// unbound one may still get a bound access token.
+	confirmation := refreshClaims.Confirmation
+	thumbprint := dpop.ThumbprintFromContext(ctx)
+	if confirmation != nil && confirmation.JKT != thumbprint {
+		service.rejectRenewal(ctx, user.ID, session.ID, "dpop_mismatch", exception.NewUnauthorizedError("DPoP proof does not match the refresh token"))
+	}
+	if confirmation == nil && thumbprint != "" {
+		confirmation = &web.Confirmation{JKT: thumbprint}
+	}
+
+	session, accessTokenTTL := service.SessionIssuer.ExtendSession(ctx, user.ID, session)
+
+	accessToken, accessClaims, err := service.UserToken.GenerateToken(web.UserClaims{
+		ID: user.ID,
+		Username: user.Username,
+		Email: user.Email,
+		Role: user.Role,
+		TokenType: web.TokenTypeAccess,
+		AuthMethods: refreshClaims.AuthMethods,
+		Acr: refreshClaims.Acr,
+		AuthTime: refreshClaims.AuthTime,
+		SessionID: session.ID,
+		TokenVersion: user.TokenVersion,
+		Confirmation: confirmation,
+	}, accessTokenTTL)
+	helper.ErrorConditionCheck(err)
+
+	recordAudit(ctx, service.AuditRecorder, audit.Event{
+		Type:      audit.EventSessionRenewed,
+		ActorID:   user.ID,
+		UserID:    user.ID,
+		SessionID: session.ID,
+	})
+	return helper.ToRenewSessionResponse(accessToken, accessClaims, session)
+
+}
+
+// RevokeSession revokes the given session, or the caller's current one.
+// Users may only revoke their own sessions; admins may revoke any.
+func (service *UserServiceImpl) RevokeSession(ctx context.Context, claims *web.UserClaims, request web.RevokeSessionRequest) {
+	err := service.Validate.Struct(request)
+	helper.ErrorConditionCheck(err)
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	sessionId := request.SessionId
+	if sessionId == "" {
+		sessionId = service.currentSessionId(claims, request.RefreshToken)
+	}
+
+	session := service.findOwnedSession(ctx, claims, sessionId)
+	service.SessionStore.Revoke(ctx, session.ID)
+
+	owner, err := service.UserRepository.FindByEmail(ctx, tx, session.User_Email)
+	if err == nil {
+		service.recordSessionRevoked(ctx, tx, owner.ID, session.ID, "revoke")
+	}
+}
+
+func (service *UserServiceImpl) currentSessionId(claims *web.UserClaims, refreshToken string) string {
+	if claims.SessionID != "" {
+		return claims.SessionID
+	}
+
+	refreshClaims := validateTokenSafely(service.UserToken, refreshToken)
+	if refreshClaims == nil || refreshClaims.TokenType != web.TokenTypeRefresh {
+		panic(exception.NewNotFoundError("session not found"))
+	}
+	return refreshClaims.RegisteredClaims.ID
+}
+
+// findOwnedSession answers 404 for sessions of other users, so their ids
+// cannot be probed.
+func (service *UserServiceImpl) findOwnedSession(ctx context.Context, claims *web.UserClaims, sessionId string) domain.Session {
+	session, err := service.SessionStore.Get(ctx, sessionId)
+	if err != nil || (session.User_Email != claims.Email && claims.Role != domain.RoleAdmin) {
+		panic(exception.NewNotFoundError("session not found"))
+	}
+	return session
+}
+
+// Reauthenticate confirms the caller's credentials again and returns an
+// access token with a fresh auth_time, as demanded by step-up protected
+// endpoints. Accounts with TOTP must also pass the second factor.
+func (service *UserServiceImpl) Reauthenticate(ctx context.Context, claims *web.UserClaims, request web.ReauthenticateRequest) web.RenewAccessTokenResponse {
+	err := service.Validate.Struct(request)
+	helper.ErrorConditionCheck(err)
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	user, err := service.UserRepository.FindById(ctx, tx, claims.ID)
+	if err != nil {
+		panic(exception.NewUnauthorizedError("invalid credentials"))
+	}
+
+	accountKey := AccountKeyForUser(user.ID)
+	service.LoginLockoutService.CheckAllowed(ctx, accountKey)
+
+	valid, err := service.PasswordHasher.Verify(ctx, user.Password, request.Password)
+	hasherErrorCheck(err)
+	if !valid {
+		service.LoginLockoutService.RecordFailure(ctx, &user, accountKey)
+		service.recordReauthenticationFailure(ctx, user.ID, "invalid_password")
+		panic(exception.NewUnauthorizedError("invalid credentials"))
+	}
+
+	authentication := NewAuthentication(web.AmrPassword)
+	if user.TotpEnabled {
+		if request.Code == "" && request.RecoveryCode == "" {
+			panic(exception.NewValidationError([]web.FieldError{{
+				Field:   "code",
+				Rule:    "required",
+				Message: "a verification code is required for this account",
+			}}))
+		}
+		method, ok := service.MfaService.VerifySecondFactor(ctx, tx, user, request.Code, request.RecoveryCode)
+		if !ok {
+			service.LoginLockoutService.RecordFailure(ctx, &user, accountKey)
+			service.recordReauthenticationFailure(ctx, user.ID, "invalid_second_factor")
+			panic(exception.NewUnauthorizedError("invalid credentials"))
+		}
+		authentication = authentication.WithSecondFactor(method)
+	}
+	service.LoginLockoutService.RecordSuccess(ctx, accountKey)
+
+	session, err := service.SessionStore.Get(ctx, claims.SessionID)
+	if err != nil {
+		panic(exception.NewUnauthorizedError("session expired"))
+	}
+	accessToken, accessClaims := service.SessionIssuer.ReissueAccessToken(user, session, authentication, claims.Confirmation)
+
+	recordAudit(ctx, service.AuditRecorder, audit.Event{
+		Type:      audit.EventReauthentication,
+		UserID:    user.ID,
+		SessionID: session.ID,
+		Metadata: map[string]string{
+			"methods": strings.Join(authentication.Methods, ","),
+			"acr":     authentication.Acr(),
+		},
+	})
 	return helper.ToRenewAccessTokenResponse(accessToken, accessClaims)
+}
+
+// DeleteAccount removes the user and everything that belongs to them. The
+// route is protected by step-up authentication.
+func (service *UserServiceImpl) DeleteAccount(ctx context.Context, userId int) {
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	user, err := service.UserRepository.FindById(ctx, tx, userId)
+	if err != nil {
+		panic(exception.NewNotFoundError(err.Error()))
+	}
 
+	service.SessionStore.DeleteAllForUser(ctx, user.Email)
+	service.UserRepository.Delete(ctx, tx, user)
 }
 
-func (service *UserServiceImpl) RevokeSession(ctx context.Context, sessionId string) {
+// LogoutAll revokes every session of the user and bumps their token version,
+// so access tokens that are still unexpired are rejected as well. Trusted
+// devices are revoked too, so the next login asks for MFA again.
+func (service *UserServiceImpl) LogoutAll(ctx context.Context, userId int) {
 	tx, err := service.DB.Begin()
 	helper.ErrorConditionCheck(err)
 	defer helper.CommitOrRollback(tx)
 
-	service.UserRepository.RevokeSession(ctx, tx, sessionId)
+	user, err := service.UserRepository.FindById(ctx, tx, userId)
+	if err != nil {
+		panic(exception.NewNotFoundError(err.Error()))
+	}
+
+	service.SessionStore.RevokeAllForUser(ctx, user.Email)
+	service.UserRepository.IncrementTokenVersion(ctx, tx, user.ID)
+	service.TrustedDeviceService.RevokeAll(ctx, tx, user.ID)
+	recordAudit(ctx, service.AuditRecorder, audit.Event{
+		Type:   audit.EventSessionsRevokedAll,
+		UserID: user.ID,
+	})
+	service.WebhookService.Publish(ctx, tx, webhook.EventSessionRevoked, map[string]interface{}{
+		"user_id":      user.ID,
+		"all_sessions": true,
+		"reason":       "logout_all",
+	})
+}
+
+// CurrentTokenVersion is used by the auth middleware to reject access tokens
+// issued before the last LogoutAll. It runs on every authenticated request,
+// so it reads outside of a transaction.
+func (service *UserServiceImpl) CurrentTokenVersion(ctx context.Context, userId int) (int, error) {
+	return service.UserRepository.FindTokenVersion(ctx, service.DB, userId)
+}
+
+// FindSessions lists the user's active sessions; the one matching
+// currentSessionId (the caller's sid claim) is marked as current.
+func (service *UserServiceImpl) FindSessions(ctx context.Context, userId int, currentSessionId string) []web.SessionResponse {
+	tx, err := service.DB.Begin()
 	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
 
+	user, err := service.UserRepository.FindById(ctx, tx, userId)
+	if err != nil {
+		panic(exception.NewNotFoundError(err.Error()))
+	}
+
+	sessions := service.SessionStore.FindByEmail(ctx, user.Email)
+	return helper.ToSessionResponses(sessions, currentSessionId)
+}
+
+// DeleteSession revokes one of the user's own sessions. Sessions of other
+// users are reported as not found.
+func (service *UserServiceImpl) DeleteSession(ctx context.Context, userId int, sessionId string) {
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	user, err := service.UserRepository.FindById(ctx, tx, userId)
+	if err != nil {
+		panic(exception.NewNotFoundError(err.Error()))
+	}
+
+	err = service.SessionStore.RevokeForUser(ctx, sessionId, user.Email)
+	if err != nil {
+		panic(exception.NewNotFoundError(err.Error()))
+	}
+	service.recordSessionRevoked(ctx, tx, user.ID, sessionId, "revoke")
 }
 
 func (service *UserServiceImpl) FindById(ctx context.Context, userId int) web.UserResponse {
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"golang_jwt/model/web"
+	"golang_jwt/webhook"
+	"time"
+)
+
+// WebhookService notifies registered endpoints of account events. Publish
+// writes the event to the outbox inside the caller's transaction, so it is
+// only sent once the change it describes is committed; Dispatch, run by
+// the scheduler, turns the outbox into signed deliveries and retries them.
+// PublishSeparately commits the event on its own, for requests that fail
+// and roll their transaction back.
+type WebhookService interface {
+	Publish(ctx context.Context, tx *sql.Tx, eventType webhook.EventType, data map[string]interface{})
+	PublishSeparately(ctx context.Context, eventType webhook.EventType, data map[string]interface{})
+	CreateEndpoint(ctx context.Context, request web.WebhookEndpointCreateRequest) web.WebhookEndpointResponse
+	FindEndpoints(ctx context.Context) []web.WebhookEndpointResponse
+	DeleteEndpoint(ctx context.Context, webhookId int64)
+	FindDeliveries(ctx context.Context, request web.WebhookDeliveryQueryRequest) []web.WebhookDeliveryResponse
+	Redeliver(ctx context.Context, deliveryId int64) web.WebhookDeliveryResponse
+	Dispatch(ctx context.Context)
+}
+
+// WebhookConfig controls delivery. A failed attempt is retried after
+// RetryBaseDelay, doubling per attempt up to RetryMaxDelay; after
+// MaxAttempts the delivery is dead.
+type WebhookConfig struct {
+	EncryptionKey    []byte
+	DispatchInterval time.Duration
+	BatchSize        int
+	MaxAttempts      int
+	RetryBaseDelay   time.Duration
+	RetryMaxDelay    time.Duration
+	Sender           webhook.Config
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"golang_jwt/exception"
+	"golang_jwt/helper"
+	"golang_jwt/model/domain"
+	"golang_jwt/model/web"
+	"golang_jwt/repository"
+	"golang_jwt/webhook"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+type WebhookServiceImpl struct {
+	WebhookRepository repository.WebhookRepository
+	DB                *sql.DB
+	Validate          *validator.Validate
+	Sender            webhook.Sender
+	Config            WebhookConfig
+}
+
+func NewWebhookService(webhookRepository repository.WebhookRepository, DB *sql.DB, Validate *validator.Validate, sender webhook.Sender, config WebhookConfig) WebhookService {
+	return &WebhookServiceImpl{
+		WebhookRepository: webhookRepository,
+		DB:                DB,
+		Validate:          Validate,
+		Sender:            sender,
+		Config:            config,
+	}
+}
+
+const (
+	defaultWebhookDeliveryLimit = 50
+	maxWebhookDeliveryLimit     = 500
+)
+
+func (service *WebhookServiceImpl) Publish(ctx context.Context, tx *sql.Tx, eventType webhook.EventType, data map[string]interface{}) {
+	clientInfo := helper.ClientInfoFromContext(ctx)
+	payload := webhook.Payload{
+		ID:         uuid.New().String(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		IPAddress:  clientInfo.IPAddress,
+		UserAgent:  clientInfo.UserAgent,
+		Data:       data,
+	}
+
+	body, err := json.Marshal(payload)
+	helper.ErrorConditionCheck(err)
+
+	service.WebhookRepository.SaveOutboxEvent(ctx, tx, domain.WebhookOutboxEvent{
+		EventID:   payload.ID,
+		EventType: string(eventType),
+		Payload:   string(body),
+	})
+}
+
+func (service *WebhookServiceImpl) PublishSeparately(ctx context.Context, eventType webhook.EventType, data map[string]interface{}) {
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	service.Publish(ctx, tx, eventType, data)
+}
+
+// CreateEndpoint returns the signing secret once; only its encrypted form
+// is stored.
+func (service *WebhookServiceImpl) CreateEndpoint(ctx context.Context, request web.WebhookEndpointCreateRequest) web.WebhookEndpointResponse {
+	err := service.Validate.Struct(request)
+	helper.ErrorConditionCheck(err)
+
+	endpointURL, err := url.Parse(request.URL)
+	if err != nil || (endpointURL.Scheme != "https" && endpointURL.Scheme != "http") || endpointURL.Host == "" {
+		panic(exception.NewValidationError([]web.FieldError{{
+			Field:   "url",
+			Rule:    "url",
+			Message: "url must be an http or https URL",
+		}}))
+	}
+
+	secret := "whsec_" + helper.GenerateRandomToken(32)
+	encryptedSecret, err := helper.EncryptSecret(service.Config.EncryptionKey, secret)
+	helper.ErrorConditionCheck(err)
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	endpoint := service.WebhookRepository.SaveEndpoint(ctx, tx, domain.WebhookEndpoint{
+		URL:         request.URL,
+		Secret:      encryptedSecret,
+		Events:      slices.Compact(slices.Sorted(slices.Values(request.Events))),
+		Description: request.Description,
+	})
+
+	webhookEndpointResponse := helper.ToWebhookEndpointResponse(endpoint)
+	webhookEndpointResponse.Secret = secret
+	return webhookEndpointResponse
+}
+
+func (service *WebhookServiceImpl) FindEndpoints(ctx context.Context) []web.WebhookEndpointResponse {
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	endpoints := service.WebhookRepository.FindEndpoints(ctx, tx)
+	return helper.ToWebhookEndpointResponses(endpoints)
+}
+
+func (service *WebhookServiceImpl) DeleteEndpoint(ctx context.Context, webhookId int64) {
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	err = service.WebhookRepository.DeleteEndpoint(ctx, tx, webhookId)
+	if err != nil {
+		panic(exception.NewNotFoundError(err.Error()))
+	}
+}
+
+func (service *WebhookServiceImpl) FindDeliveries(ctx context.Context, request web.WebhookDeliveryQueryRequest) []web.WebhookDeliveryResponse {
+	err := service.Validate.Struct(request)
+	helper.ErrorConditionCheck(err)
+
+	filter := domain.WebhookDeliveryFilter{
+		EndpointID: parseQueryNumber("webhook_id", request.WebhookId, 64),
+		Status:     request.Status,
+		EventType:  request.EventType,
+		BeforeID:   parseQueryNumber("before_id", request.BeforeId, 64),
+		Limit:      defaultWebhookDeliveryLimit,
+	}
+	if limit := int(parseQueryNumber("limit", request.Limit, 32)); limit > 0 {
+		filter.Limit = min(limit, maxWebhookDeliveryLimit)
+	}
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	deliveries := service.WebhookRepository.FindDeliveries(ctx, tx, filter)
+	return helper.ToWebhookDeliveryResponses(deliveries)
+}
+
+// Redeliver schedules a finished delivery, usually a dead one, for another
+// full round of attempts. It keeps its event id, so receivers that already
+// processed it can recognize the duplicate. Pending deliveries are left as
+// they are, as one may be in flight.
+func (service *WebhookServiceImpl) Redeliver(ctx context.Context, deliveryId int64) web.WebhookDeliveryResponse {
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	delivery, err := service.WebhookRepository.FindDeliveryById(ctx, tx, deliveryId)
+	if err != nil {
+		panic(exception.NewNotFoundError(err.Error()))
+	}
+
+	if delivery.Status != domain.WebhookDeliveryPending {
+		delivery.Status = domain.WebhookDeliveryPending
+		delivery.Attempts = 0
+		delivery.NextAttemptAt = time.Now()
+		service.WebhookRepository.UpdateDelivery(ctx, tx, delivery)
+	}
+	return helper.ToWebhookDeliveryResponse(delivery)
+}
+
+// Dispatch drains the outbox and then sends up to BatchSize due deliveries.
+// Several instances may dispatch at once; rows are claimed with SKIP LOCKED.
+func (service *WebhookServiceImpl) Dispatch(ctx context.Context) {
+	for {
+		if service.fanOut(ctx) < service.Config.BatchSize {
+			break
+		}
+	}
+
+	for i := 0; i < service.Config.BatchSize; i++ {
+		delivery, endpoint, ok := service.claimDelivery(ctx)
+		if !ok {
+			return
+		}
+		statusCode, err := service.send(ctx, delivery, endpoint)
+		service.recordAttempt(ctx, delivery, statusCode, err)
+	}
+}
+
+// fanOut creates a delivery for every endpoint subscribed to the oldest
+// outbox events and returns how many events it took.
+func (service *WebhookServiceImpl) fanOut(ctx context.Context) int {
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	events := service.WebhookRepository.TakeOutboxEvents(ctx, tx, service.Config.BatchSize)
+	if len(events) == 0 {
+		return 0
+	}
+
+	endpoints := service.WebhookRepository.FindEndpoints(ctx, tx)
+	now := time.Now()
+	for _, event := range events {
+		for _, endpoint := range endpoints {
+			if !slices.Contains(endpoint.Events, event.EventType) {
+				continue
+			}
+			service.WebhookRepository.SaveDelivery(ctx, tx, domain.WebhookDelivery{
+				EndpointID:    endpoint.ID,
+				EventID:       event.EventID,
+				EventType:     event.EventType,
+				Payload:       event.Payload,
+				Status:        domain.WebhookDeliveryPending,
+				NextAttemptAt: now,
+			})
+		}
+	}
+	return len(events)
+}
+
+// claimDelivery leases a due delivery for twice the request timeout, so it
+// is retried by any instance if this one dies before recording the result.
+func (service *WebhookServiceImpl) claimDelivery(ctx context.Context) (domain.WebhookDelivery, domain.WebhookEndpoint, bool) {
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	now := time.Now()
+	delivery, ok := service.WebhookRepository.ClaimDueDelivery(ctx, tx, now, now.Add(2*service.Config.Sender.Timeout))
+	if !ok {
+		return delivery, domain.WebhookEndpoint{}, false
+	}
+
+	endpoint, err := service.WebhookRepository.FindEndpointById(ctx, tx, delivery.EndpointID)
+	helper.ErrorConditionCheck(err)
+	return delivery, endpoint, true
+}
+
+func (service *WebhookServiceImpl) send(ctx context.Context, delivery domain.WebhookDelivery, endpoint domain.WebhookEndpoint) (int, error) {
+	secret, err := helper.DecryptSecret(service.Config.EncryptionKey, endpoint.Secret)
+	if err != nil {
+		return 0, fmt.Errorf("decrypting secret: %w", err)
+	}
+
+	return service.Sender.Send(ctx, webhook.Message{
+		ID:     delivery.EventID,
+		Event:  webhook.EventType(delivery.EventType),
+		URL:    endpoint.URL,
+		Secret: secret,
+		Body:   []byte(delivery.Payload),
+	})
+}
+
+// recordAttempt counts any 2xx response as delivered. Other responses and
+// transport errors are retried with exponential backoff until MaxAttempts.
+func (service *WebhookServiceImpl) recordAttempt(ctx context.Context, delivery domain.WebhookDelivery, statusCode int, err error) {
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = ""
+	if err != nil {
+		delivery.LastError = err.Error()
+	} else if statusCode < 200 || statusCode >= 300 {
+		delivery.LastError = fmt.Sprintf("unexpected response status %d", statusCode)
+	}
+
+	switch {
+	case delivery.LastError == "":
+		delivery.Status = domain.WebhookDeliverySucceeded
+	case delivery.Attempts >= service.Config.MaxAttempts:
+		delivery.Status = domain.WebhookDeliveryDead
+	default:
+		delivery.NextAttemptAt = time.Now().Add(service.retryDelay(delivery.Attempts))
+	}
+
+	tx, err := service.DB.Begin()
+	helper.ErrorConditionCheck(err)
+	defer helper.CommitOrRollback(tx)
+
+	service.WebhookRepository.UpdateDelivery(ctx, tx, delivery)
+}
+
+func (service *WebhookServiceImpl) retryDelay(attempts int) time.Duration {
+	delay := service.Config.RetryBaseDelay
+	for i := 1; i < attempts && delay < service.Config.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > service.Config.RetryMaxDelay {
+		delay = service.Config.RetryMaxDelay
+	}
+	return delay
+}
@@ -7,6 +7,6 @@ import (
 
 
 type UserToken interface {
-	GenerateToken(id int, username string, email string, duration time.Duration) (string, *web.UserClaims, error)
+	GenerateToken(claims web.UserClaims, duration time.Duration) (string, *web.UserClaims, error)
 	ValidateToken(tokenString string) (*web.UserClaims, error)
 }
\ No newline at end of file
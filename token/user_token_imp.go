@@ -20,28 +20,24 @@ func NewUserToken(secretKey string) UserToken {
     }
 }
 
-func (userToken *UserTokenImpl) GenerateToken(id int, username string, email string, duration time.Duration) (string, *web.UserClaims, error) {
+// GenerateToken signs the identity fields of claims; the registered claims
+// (jti, sub, iat, exp) are always set here.
+func (userToken *UserTokenImpl) GenerateToken(claims web.UserClaims, duration time.Duration) (string, *web.UserClaims, error) {
     tokenID, err := uuid.NewRandom()
     helper.ErrorConditionCheck(err)
 
-    claims := &web.UserClaims{
-        ID: id,
-        Username: username,
-        Email: email,
-        RegisteredClaims: jwt.RegisteredClaims{
-            ID: tokenID.String(),
-            Subject: email,
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
-            
-        },
+    claims.RegisteredClaims = jwt.RegisteredClaims{
+        ID: tokenID.String(),
+        Subject: claims.Email,
+        IssuedAt:  jwt.NewNumericDate(time.Now()),
+        ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
     }
 
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+    token := jwt.NewWithClaims(jwt.SigningMethodHS256, &claims)
     tokenString, err := token.SignedString([]byte(userToken.SecretKey))
     helper.ErrorConditionCheck(err)
 
-    return tokenString, claims, nil
+    return tokenString, &claims, nil
 }
 
 func (userToken *UserTokenImpl) ValidateToken(tokenString string) (*web.UserClaims, error) {
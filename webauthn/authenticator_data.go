@@ -0,0 +1,81 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	FlagUserPresent            byte = 0x01
+	FlagUserVerified           byte = 0x04
+	FlagBackupEligible         byte = 0x08
+	FlagBackupState            byte = 0x10
+	FlagAttestedCredentialData byte = 0x40
+	FlagExtensionData          byte = 0x80
+)
+
+const maxCredentialIDLength = 1023
+
+var ErrInvalidAuthenticatorData = errors.New("webauthn: invalid authenticator data")
+
+type AuthenticatorData struct {
+	RPIDHash            []byte
+	Flags               byte
+	SignCount           uint32
+	AAGUID              []byte
+	CredentialID        []byte
+	CredentialPublicKey []byte
+}
+
+func (authData AuthenticatorData) HasFlag(flag byte) bool {
+	return authData.Flags&flag == flag
+}
+
+// ParseAuthenticatorData follows the layout in WebAuthn Level 2 §6.1:
+// rpIdHash (32) | flags (1) | signCount (4) | [attestedCredentialData] | [extensions].
+func ParseAuthenticatorData(data []byte) (AuthenticatorData, error) {
+	authData := AuthenticatorData{}
+	if len(data) < 37 {
+		return authData, ErrInvalidAuthenticatorData
+	}
+
+	authData.RPIDHash = data[:32]
+	authData.Flags = data[32]
+	authData.SignCount = binary.BigEndian.Uint32(data[33:37])
+	rest := data[37:]
+
+	if authData.HasFlag(FlagAttestedCredentialData) {
+		if len(rest) < 18 {
+			return authData, ErrInvalidAuthenticatorData
+		}
+		authData.AAGUID = rest[:16]
+		credentialIDLength := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+
+		if credentialIDLength > maxCredentialIDLength || len(rest) < credentialIDLength {
+			return authData, ErrInvalidAuthenticatorData
+		}
+		authData.CredentialID = rest[:credentialIDLength]
+		rest = rest[credentialIDLength:]
+
+		_, consumed, err := decodeCBOR(rest)
+		if err != nil {
+			return authData, ErrInvalidAuthenticatorData
+		}
+		authData.CredentialPublicKey = rest[:consumed]
+		rest = rest[consumed:]
+	}
+
+	if authData.HasFlag(FlagExtensionData) {
+		_, consumed, err := decodeCBOR(rest)
+		if err != nil {
+			return authData, ErrInvalidAuthenticatorData
+		}
+		rest = rest[consumed:]
+	}
+
+	if len(rest) != 0 {
+		return authData, ErrInvalidAuthenticatorData
+	}
+	return authData, nil
+}
@@ -0,0 +1,176 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+var ErrInvalidCBOR = errors.New("webauthn: invalid CBOR data")
+
+const maxCBORDepth = 16
+
+// decodeCBOR decodes the subset of CBOR used by WebAuthn (RFC 8949 without
+// indefinite lengths). Integers are returned as int64, byte strings as
+// []byte, maps as map[interface{}]interface{}. It also reports how many bytes
+// the first data item occupied, since authenticator data embeds a COSE key
+// followed by more fields.
+func decodeCBOR(data []byte) (interface{}, int, error) {
+	decoder := &cborDecoder{data: data}
+	value, err := decoder.decode(0)
+	if err != nil {
+		return nil, 0, err
+	}
+	return value, decoder.pos, nil
+}
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (decoder *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxCBORDepth {
+		return nil, ErrInvalidCBOR
+	}
+	if decoder.pos >= len(decoder.data) {
+		return nil, ErrInvalidCBOR
+	}
+
+	initial := decoder.data[decoder.pos]
+	decoder.pos++
+	major, info := initial>>5, initial&0x1f
+
+	if major == 7 {
+		return decoder.decodeSimple(info)
+	}
+
+	argument, err := decoder.readArgument(info)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case 0:
+		if argument > math.MaxInt64 {
+			return nil, ErrInvalidCBOR
+		}
+		return int64(argument), nil
+	case 1:
+		if argument > math.MaxInt64 {
+			return nil, ErrInvalidCBOR
+		}
+		return -1 - int64(argument), nil
+	case 2:
+		return decoder.readBytes(argument)
+	case 3:
+		bytes, err := decoder.readBytes(argument)
+		if err != nil {
+			return nil, err
+		}
+		return string(bytes), nil
+	case 4:
+		if argument > uint64(len(decoder.data)-decoder.pos) {
+			return nil, ErrInvalidCBOR
+		}
+		array := make([]interface{}, 0, argument)
+		for i := uint64(0); i < argument; i++ {
+			item, err := decoder.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, item)
+		}
+		return array, nil
+	case 5:
+		if argument > uint64(len(decoder.data)-decoder.pos) {
+			return nil, ErrInvalidCBOR
+		}
+		object := make(map[interface{}]interface{}, argument)
+		for i := uint64(0); i < argument; i++ {
+			key, err := decoder.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, ErrInvalidCBOR
+			}
+			value, err := decoder.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			object[key] = value
+		}
+		return object, nil
+	case 6:
+		return decoder.decode(depth + 1)
+	}
+	return nil, ErrInvalidCBOR
+}
+
+func (decoder *cborDecoder) readArgument(info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		bytes, err := decoder.readBytes(1)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(bytes[0]), nil
+	case info == 25:
+		bytes, err := decoder.readBytes(2)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint16(bytes)), nil
+	case info == 26:
+		bytes, err := decoder.readBytes(4)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint32(bytes)), nil
+	case info == 27:
+		bytes, err := decoder.readBytes(8)
+		if err != nil {
+			return 0, err
+		}
+		return binary.BigEndian.Uint64(bytes), nil
+	}
+	return 0, ErrInvalidCBOR
+}
+
+func (decoder *cborDecoder) decodeSimple(info byte) (interface{}, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 26:
+		bytes, err := decoder.readBytes(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(bytes))), nil
+	case 27:
+		bytes, err := decoder.readBytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(bytes)), nil
+	}
+	return nil, ErrInvalidCBOR
+}
+
+func (decoder *cborDecoder) readBytes(length uint64) ([]byte, error) {
+	if length > uint64(len(decoder.data)-decoder.pos) {
+		return nil, ErrInvalidCBOR
+	}
+	bytes := decoder.data[decoder.pos : decoder.pos+int(length)]
+	decoder.pos += int(length)
+	return bytes, nil
+}
@@ -0,0 +1,105 @@
+package webauthn
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeCBOR(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  interface{}
+	}{
+		{"small unsigned", "17", int64(23)},
+		{"one byte unsigned", "1818", int64(24)},
+		{"two byte unsigned", "1903e8", int64(1000)},
+		{"four byte unsigned", "1a000f4240", int64(1000000)},
+		{"eight byte unsigned", "1b000000e8d4a51000", int64(1000000000000)},
+		{"negative", "20", int64(-1)},
+		{"COSE algorithm", "26", int64(-7)},
+		{"large negative", "390100", int64(-257)},
+		{"byte string", "4401020304", []byte{1, 2, 3, 4}},
+		{"text string", "6449455446", "IETF"},
+		{"array", "83010203", []interface{}{int64(1), int64(2), int64(3)}},
+		{"map", "a201020304", map[interface{}]interface{}{int64(1): int64(2), int64(3): int64(4)}},
+		{"text keys", "a26161016162820203", map[interface{}]interface{}{"a": int64(1), "b": []interface{}{int64(2), int64(3)}}},
+		{"false", "f4", false},
+		{"true", "f5", true},
+		{"null", "f6", nil},
+		{"tag", "c11a514b67b0", int64(1363896240)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			input, _ := hex.DecodeString(test.input)
+			got, consumed, err := decodeCBOR(input)
+			if err != nil {
+				t.Fatalf("decodeCBOR(%s) returned error: %v", test.input, err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("decodeCBOR(%s) = %#v, want %#v", test.input, got, test.want)
+			}
+			if consumed != len(input) {
+				t.Errorf("decodeCBOR(%s) consumed %d bytes, want %d", test.input, consumed, len(input))
+			}
+		})
+	}
+}
+
+func TestDecodeCBORReportsConsumedBytes(t *testing.T) {
+	input := []byte{0x42, 0xaa, 0xbb, 0xff, 0xff}
+	got, consumed, err := decodeCBOR(input)
+	if err != nil {
+		t.Fatalf("decodeCBOR returned error: %v", err)
+	}
+	if !bytes.Equal(got.([]byte), []byte{0xaa, 0xbb}) || consumed != 3 {
+		t.Errorf("decodeCBOR = %x, %d; want aabb, 3", got, consumed)
+	}
+}
+
+func TestDecodeCBORRejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"truncated argument", "19e8"},
+		{"truncated byte string", "44010203"},
+		{"indefinite length", "5f4101ff"},
+		{"reserved additional info", "1c"},
+		{"array longer than input", "9a7fffffff"},
+		{"map longer than input", "ba7fffffff"},
+		{"unsigned out of range", "1bffffffffffffffff"},
+		{"float map key", "a1fa3f80000001"},
+		{"array map key", "a1800101"},
+		{"missing map value", "a101"},
+		{"unassigned simple value", "f0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			input, _ := hex.DecodeString(test.input)
+			_, _, err := decodeCBOR(input)
+			if err != ErrInvalidCBOR {
+				t.Errorf("decodeCBOR(%s) error = %v, want %v", test.input, err, ErrInvalidCBOR)
+			}
+		})
+	}
+}
+
+func TestDecodeCBORLimitsNesting(t *testing.T) {
+	input := append(bytes.Repeat([]byte{0x81}, maxCBORDepth+1), 0x01)
+	_, _, err := decodeCBOR(input)
+	if err != ErrInvalidCBOR {
+		t.Errorf("decodeCBOR of %d nested arrays error = %v, want %v", maxCBORDepth+1, err, ErrInvalidCBOR)
+	}
+
+	input = append(bytes.Repeat([]byte{0x81}, maxCBORDepth), 0x01)
+	_, _, err = decodeCBOR(input)
+	if err != nil {
+		t.Errorf("decodeCBOR of %d nested arrays returned error: %v", maxCBORDepth, err)
+	}
+}
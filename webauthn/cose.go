@@ -0,0 +1,138 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// COSE algorithm identifiers (RFC 9053) accepted for credentials.
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+const (
+	coseKeyType      int64 = 1
+	coseKeyAlgorithm int64 = 3
+	coseKeyCurve     int64 = -1
+	coseKeyX         int64 = -2
+	coseKeyY         int64 = -3
+	coseKeyN         int64 = -1
+	coseKeyE         int64 = -2
+
+	coseKeyTypeOKP int64 = 1
+	coseKeyTypeEC2 int64 = 2
+	coseKeyTypeRSA int64 = 3
+
+	coseCurveP256    int64 = 1
+	coseCurveEd25519 int64 = 6
+)
+
+var (
+	ErrUnsupportedKey = errors.New("webauthn: unsupported credential public key")
+	ErrBadSignature   = errors.New("webauthn: signature verification failed")
+)
+
+type PublicKey struct {
+	Algorithm int64
+	Key       crypto.PublicKey
+}
+
+// ParsePublicKey decodes a COSE_Key as stored for a credential.
+func ParsePublicKey(coseKey []byte) (PublicKey, error) {
+	value, _, err := decodeCBOR(coseKey)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	object, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return PublicKey{}, ErrUnsupportedKey
+	}
+
+	keyType, _ := object[coseKeyType].(int64)
+	algorithm, _ := object[coseKeyAlgorithm].(int64)
+
+	switch {
+	case keyType == coseKeyTypeEC2 && algorithm == AlgES256:
+		curve, _ := object[coseKeyCurve].(int64)
+		x, _ := object[coseKeyX].([]byte)
+		y, _ := object[coseKeyY].([]byte)
+		if curve != coseCurveP256 || len(x) != 32 || len(y) != 32 {
+			return PublicKey{}, ErrUnsupportedKey
+		}
+
+		// ecdh rejects points that are not on the curve.
+		_, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...))
+		if err != nil {
+			return PublicKey{}, ErrUnsupportedKey
+		}
+
+		return PublicKey{
+			Algorithm: algorithm,
+			Key: &ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(x),
+				Y:     new(big.Int).SetBytes(y),
+			},
+		}, nil
+
+	case keyType == coseKeyTypeOKP && algorithm == AlgEdDSA:
+		curve, _ := object[coseKeyCurve].(int64)
+		x, _ := object[coseKeyX].([]byte)
+		if curve != coseCurveEd25519 || len(x) != ed25519.PublicKeySize {
+			return PublicKey{}, ErrUnsupportedKey
+		}
+		return PublicKey{Algorithm: algorithm, Key: ed25519.PublicKey(x)}, nil
+
+	case keyType == coseKeyTypeRSA && algorithm == AlgRS256:
+		n, _ := object[coseKeyN].([]byte)
+		e, _ := object[coseKeyE].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return PublicKey{}, ErrUnsupportedKey
+		}
+		return PublicKey{
+			Algorithm: algorithm,
+			Key: &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			},
+		}, nil
+	}
+
+	return PublicKey{}, ErrUnsupportedKey
+}
+
+func (publicKey PublicKey) Verify(data []byte, signature []byte) error {
+	return verifySignature(publicKey.Algorithm, publicKey.Key, data, signature)
+}
+
+func verifySignature(algorithm int64, key crypto.PublicKey, data []byte, signature []byte) error {
+	digest := sha256.Sum256(data)
+
+	switch algorithm {
+	case AlgES256:
+		ecdsaKey, ok := key.(*ecdsa.PublicKey)
+		if ok && ecdsa.VerifyASN1(ecdsaKey, digest[:], signature) {
+			return nil
+		}
+	case AlgEdDSA:
+		ed25519Key, ok := key.(ed25519.PublicKey)
+		if ok && ed25519.Verify(ed25519Key, data, signature) {
+			return nil
+		}
+	case AlgRS256:
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if ok && rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest[:], signature) == nil {
+			return nil
+		}
+	}
+	return ErrBadSignature
+}
@@ -0,0 +1,108 @@
+package webauthn_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"golang_jwt/webauthn"
+	"golang_jwt/webauthn/webauthntest"
+	"testing"
+)
+
+func TestParsePublicKeyES256(t *testing.T) {
+	authenticator := webauthntest.NewAuthenticator("example.com", "https://example.com")
+
+	publicKey, err := webauthn.ParsePublicKey(authenticator.PublicKey())
+	if err != nil {
+		t.Fatalf("ParsePublicKey returned error: %v", err)
+	}
+	if publicKey.Algorithm != webauthn.AlgES256 {
+		t.Errorf("Algorithm = %d, want %d", publicKey.Algorithm, webauthn.AlgES256)
+	}
+	key, ok := publicKey.Key.(*ecdsa.PublicKey)
+	if !ok || !key.Equal(&authenticator.PrivateKey.PublicKey) {
+		t.Fatalf("Key = %#v, want the authenticator's public key", publicKey.Key)
+	}
+
+	data := []byte("signed data")
+	digest := sha256.Sum256(data)
+	signature, err := ecdsa.SignASN1(rand.Reader, authenticator.PrivateKey, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := publicKey.Verify(data, signature); err != nil {
+		t.Errorf("Verify returned error for a valid signature: %v", err)
+	}
+	if err := publicKey.Verify([]byte("other data"), signature); !errors.Is(err, webauthn.ErrBadSignature) {
+		t.Errorf("Verify error = %v, want %v", err, webauthn.ErrBadSignature)
+	}
+}
+
+func TestParsePublicKeyEdDSA(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coseKey := webauthntest.EncodeCBOR(map[interface{}]interface{}{
+		int64(1):  int64(1),
+		int64(3):  webauthn.AlgEdDSA,
+		int64(-1): int64(6),
+		int64(-2): []byte(publicKey),
+	})
+
+	parsed, err := webauthn.ParsePublicKey(coseKey)
+	if err != nil {
+		t.Fatalf("ParsePublicKey returned error: %v", err)
+	}
+	data := []byte("signed data")
+	if err := parsed.Verify(data, ed25519.Sign(privateKey, data)); err != nil {
+		t.Errorf("Verify returned error for a valid signature: %v", err)
+	}
+}
+
+func TestParsePublicKeyRejectsInvalidKeys(t *testing.T) {
+	point := make([]byte, 32)
+	point[31] = 1
+
+	tests := []struct {
+		name string
+		key  map[interface{}]interface{}
+	}{
+		{"point not on curve", map[interface{}]interface{}{
+			int64(1): int64(2), int64(3): webauthn.AlgES256, int64(-1): int64(1), int64(-2): point, int64(-3): point,
+		}},
+		{"wrong curve", map[interface{}]interface{}{
+			int64(1): int64(2), int64(3): webauthn.AlgES256, int64(-1): int64(2), int64(-2): point, int64(-3): point,
+		}},
+		{"short coordinate", map[interface{}]interface{}{
+			int64(1): int64(2), int64(3): webauthn.AlgES256, int64(-1): int64(1), int64(-2): point[1:], int64(-3): point,
+		}},
+		{"algorithm does not match key type", map[interface{}]interface{}{
+			int64(1): int64(2), int64(3): webauthn.AlgEdDSA, int64(-1): int64(1), int64(-2): point, int64(-3): point,
+		}},
+		{"unsupported algorithm", map[interface{}]interface{}{
+			int64(1): int64(2), int64(3): int64(-35), int64(-1): int64(2), int64(-2): point, int64(-3): point,
+		}},
+		{"short RSA modulus", map[interface{}]interface{}{
+			int64(1): int64(3), int64(3): webauthn.AlgRS256, int64(-1): make([]byte, 128), int64(-2): []byte{1, 0, 1},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := webauthn.ParsePublicKey(webauthntest.EncodeCBOR(test.key))
+			if !errors.Is(err, webauthn.ErrUnsupportedKey) {
+				t.Errorf("ParsePublicKey error = %v, want %v", err, webauthn.ErrUnsupportedKey)
+			}
+		})
+	}
+}
+
+func TestParsePublicKeyRejectsMalformedCBOR(t *testing.T) {
+	_, err := webauthn.ParsePublicKey([]byte{0xa5, 0x01})
+	if !errors.Is(err, webauthn.ErrInvalidCBOR) {
+		t.Errorf("ParsePublicKey error = %v, want %v", err, webauthn.ErrInvalidCBOR)
+	}
+}
@@ -0,0 +1,267 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidClientData      = errors.New("webauthn: invalid client data")
+	ErrChallengeMismatch      = errors.New("webauthn: challenge mismatch")
+	ErrOriginMismatch         = errors.New("webauthn: origin not allowed")
+	ErrRPIDMismatch           = errors.New("webauthn: relying party ID mismatch")
+	ErrUserNotPresent         = errors.New("webauthn: user presence flag not set")
+	ErrUserNotVerified        = errors.New("webauthn: user verification required")
+	ErrInvalidAttestation     = errors.New("webauthn: invalid attestation")
+	ErrCredentialMismatch     = errors.New("webauthn: credential ID mismatch")
+	ErrSignCountRegression    = errors.New("webauthn: signature counter did not increase, the authenticator may be cloned")
+	ErrUnsupportedAttestation = errors.New("webauthn: unsupported attestation format")
+)
+
+var Encoding = base64.RawURLEncoding
+
+type RelyingParty struct {
+	ID                      string
+	Name                    string
+	Origins                 []string
+	Timeout                 time.Duration
+	RequireUserVerification bool
+}
+
+// Credential is what needs to be stored after a successful registration.
+type Credential struct {
+	ID             []byte
+	PublicKey      []byte
+	SignCount      uint32
+	AAGUID         []byte
+	BackupEligible bool
+	Transports     []string
+}
+
+func (relyingParty RelyingParty) userVerification() string {
+	if relyingParty.RequireUserVerification {
+		return "required"
+	}
+	return "preferred"
+}
+
+func (relyingParty RelyingParty) NewCreationOptions(challenge []byte, user UserEntity, exclude []CredentialDescriptor) CredentialCreationOptions {
+	return CredentialCreationOptions{
+		Challenge:    Encoding.EncodeToString(challenge),
+		RelyingParty: RelyingPartyEntity{ID: relyingParty.ID, Name: relyingParty.Name},
+		User:         user,
+		PubKeyCredParams: []CredentialParameter{
+			{Type: CredentialType, Algorithm: AlgES256},
+			{Type: CredentialType, Algorithm: AlgEdDSA},
+			{Type: CredentialType, Algorithm: AlgRS256},
+		},
+		Timeout:            relyingParty.Timeout.Milliseconds(),
+		ExcludeCredentials: exclude,
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:        "required",
+			RequireResidentKey: true,
+			UserVerification:   relyingParty.userVerification(),
+		},
+		Attestation: "none",
+	}
+}
+
+func (relyingParty RelyingParty) NewRequestOptions(challenge []byte, allow []CredentialDescriptor) CredentialRequestOptions {
+	return CredentialRequestOptions{
+		Challenge:        Encoding.EncodeToString(challenge),
+		Timeout:          relyingParty.Timeout.Milliseconds(),
+		RelyingPartyID:   relyingParty.ID,
+		AllowCredentials: allow,
+		UserVerification: relyingParty.userVerification(),
+	}
+}
+
+// VerifyRegistration runs the registration ceremony checks of WebAuthn Level 2
+// §7.1. Attestation statements are checked for integrity ("none" and
+// "packed"); no attestation trust anchors are evaluated.
+func (relyingParty RelyingParty) VerifyRegistration(challenge []byte, credential RegistrationCredential) (Credential, error) {
+	clientDataJSON, err := relyingParty.verifyClientData(CeremonyRegistration, challenge, credential.Response.ClientDataJSON)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	attestationObject, err := Encoding.DecodeString(credential.Response.AttestationObject)
+	if err != nil {
+		return Credential{}, ErrInvalidAttestation
+	}
+	value, _, err := decodeCBOR(attestationObject)
+	if err != nil {
+		return Credential{}, ErrInvalidAttestation
+	}
+	object, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return Credential{}, ErrInvalidAttestation
+	}
+	format, _ := object["fmt"].(string)
+	statement, _ := object["attStmt"].(map[interface{}]interface{})
+	rawAuthData, _ := object["authData"].([]byte)
+	if statement == nil || rawAuthData == nil {
+		return Credential{}, ErrInvalidAttestation
+	}
+
+	authData, err := relyingParty.verifyAuthenticatorData(rawAuthData)
+	if err != nil {
+		return Credential{}, err
+	}
+	if !authData.HasFlag(FlagAttestedCredentialData) {
+		return Credential{}, ErrInvalidAttestation
+	}
+
+	rawID, err := Encoding.DecodeString(credential.RawID)
+	if err != nil || !bytes.Equal(rawID, authData.CredentialID) {
+		return Credential{}, ErrCredentialMismatch
+	}
+
+	publicKey, err := ParsePublicKey(authData.CredentialPublicKey)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	err = verifyAttestationStatement(format, statement, publicKey, append(append([]byte{}, rawAuthData...), clientDataHash[:]...))
+	if err != nil {
+		return Credential{}, err
+	}
+
+	return Credential{
+		ID:             authData.CredentialID,
+		PublicKey:      authData.CredentialPublicKey,
+		SignCount:      authData.SignCount,
+		AAGUID:         authData.AAGUID,
+		BackupEligible: authData.HasFlag(FlagBackupEligible),
+		Transports:     credential.Response.Transports,
+	}, nil
+}
+
+// VerifyAssertion runs the authentication ceremony checks of WebAuthn Level 2
+// §7.2 against a stored credential and returns the new signature counter.
+func (relyingParty RelyingParty) VerifyAssertion(challenge []byte, publicKey []byte, storedSignCount uint32, credential AssertionCredential) (uint32, error) {
+	clientDataJSON, err := relyingParty.verifyClientData(CeremonyAuthentication, challenge, credential.Response.ClientDataJSON)
+	if err != nil {
+		return 0, err
+	}
+
+	rawAuthData, err := Encoding.DecodeString(credential.Response.AuthenticatorData)
+	if err != nil {
+		return 0, ErrInvalidAuthenticatorData
+	}
+	authData, err := relyingParty.verifyAuthenticatorData(rawAuthData)
+	if err != nil {
+		return 0, err
+	}
+
+	signature, err := Encoding.DecodeString(credential.Response.Signature)
+	if err != nil {
+		return 0, ErrBadSignature
+	}
+
+	key, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	err = key.Verify(append(append([]byte{}, rawAuthData...), clientDataHash[:]...), signature)
+	if err != nil {
+		return 0, err
+	}
+
+	if (authData.SignCount != 0 || storedSignCount != 0) && authData.SignCount <= storedSignCount {
+		return 0, ErrSignCountRegression
+	}
+
+	return authData.SignCount, nil
+}
+
+func (relyingParty RelyingParty) verifyClientData(ceremony string, challenge []byte, encodedClientData string) ([]byte, error) {
+	clientDataJSON, err := Encoding.DecodeString(encodedClientData)
+	if err != nil {
+		return nil, ErrInvalidClientData
+	}
+
+	clientData := CollectedClientData{}
+	err = json.Unmarshal(clientDataJSON, &clientData)
+	if err != nil || clientData.Type != ceremony {
+		return nil, ErrInvalidClientData
+	}
+
+	receivedChallenge, err := Encoding.DecodeString(clientData.Challenge)
+	if err != nil || subtle.ConstantTimeCompare(receivedChallenge, challenge) != 1 {
+		return nil, ErrChallengeMismatch
+	}
+
+	for _, origin := range relyingParty.Origins {
+		if clientData.Origin == origin {
+			return clientDataJSON, nil
+		}
+	}
+	return nil, ErrOriginMismatch
+}
+
+func (relyingParty RelyingParty) verifyAuthenticatorData(rawAuthData []byte) (AuthenticatorData, error) {
+	authData, err := ParseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return authData, err
+	}
+
+	rpIDHash := sha256.Sum256([]byte(relyingParty.ID))
+	if subtle.ConstantTimeCompare(authData.RPIDHash, rpIDHash[:]) != 1 {
+		return authData, ErrRPIDMismatch
+	}
+	if !authData.HasFlag(FlagUserPresent) {
+		return authData, ErrUserNotPresent
+	}
+	if relyingParty.RequireUserVerification && !authData.HasFlag(FlagUserVerified) {
+		return authData, ErrUserNotVerified
+	}
+	return authData, nil
+}
+
+func verifyAttestationStatement(format string, statement map[interface{}]interface{}, credentialKey PublicKey, signedData []byte) error {
+	switch format {
+	case "none":
+		if len(statement) != 0 {
+			return ErrInvalidAttestation
+		}
+		return nil
+
+	case "packed":
+		algorithm, _ := statement["alg"].(int64)
+		signature, _ := statement["sig"].([]byte)
+		if signature == nil {
+			return ErrInvalidAttestation
+		}
+
+		certificates, hasCertificates := statement["x5c"].([]interface{})
+		if !hasCertificates {
+			// Self attestation: signed by the credential key itself.
+			if algorithm != credentialKey.Algorithm {
+				return ErrInvalidAttestation
+			}
+			return credentialKey.Verify(signedData, signature)
+		}
+
+		if len(certificates) == 0 {
+			return ErrInvalidAttestation
+		}
+		leaf, _ := certificates[0].([]byte)
+		certificate, err := x509.ParseCertificate(leaf)
+		if err != nil {
+			return ErrInvalidAttestation
+		}
+		return verifySignature(algorithm, certificate.PublicKey, signedData, signature)
+	}
+
+	return ErrUnsupportedAttestation
+}
@@ -0,0 +1,195 @@
+package webauthn_test
+
+import (
+	"bytes"
+	"errors"
+	"golang_jwt/webauthn"
+	"golang_jwt/webauthn/webauthntest"
+	"testing"
+	"time"
+)
+
+var testChallenge = []byte("0123456789abcdef0123456789abcdef")
+
+func newRelyingParty() webauthn.RelyingParty {
+	return webauthn.RelyingParty{
+		ID:                      "example.com",
+		Name:                    "Example",
+		Origins:                 []string{"https://example.com"},
+		Timeout:                 5 * time.Minute,
+		RequireUserVerification: true,
+	}
+}
+
+func register(t *testing.T, relyingParty webauthn.RelyingParty, authenticator *webauthntest.Authenticator) webauthn.Credential {
+	t.Helper()
+	options := relyingParty.NewCreationOptions(testChallenge, webauthn.UserEntity{ID: "MQ", Name: "arthur"}, nil)
+	credential, err := relyingParty.VerifyRegistration(testChallenge, authenticator.Register(options))
+	if err != nil {
+		t.Fatalf("VerifyRegistration returned error: %v", err)
+	}
+	return credential
+}
+
+func TestVerifyRegistration(t *testing.T) {
+	relyingParty := newRelyingParty()
+	authenticator := webauthntest.NewAuthenticator("example.com", "https://example.com")
+
+	credential := register(t, relyingParty, authenticator)
+
+	if !bytes.Equal(credential.ID, authenticator.CredentialID) {
+		t.Errorf("ID = %x, want %x", credential.ID, authenticator.CredentialID)
+	}
+	if !bytes.Equal(credential.PublicKey, authenticator.PublicKey()) {
+		t.Errorf("PublicKey = %x, want the authenticator's COSE key", credential.PublicKey)
+	}
+	if credential.SignCount != 0 {
+		t.Errorf("SignCount = %d, want 0", credential.SignCount)
+	}
+	if len(credential.Transports) != 1 || credential.Transports[0] != "internal" {
+		t.Errorf("Transports = %v, want [internal]", credential.Transports)
+	}
+}
+
+func TestVerifyRegistrationRejectsInvalidResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(authenticator *webauthntest.Authenticator)
+		want   error
+	}{
+		{"wrong origin", func(authenticator *webauthntest.Authenticator) {
+			authenticator.Origin = "https://evil.example"
+		}, webauthn.ErrOriginMismatch},
+		{"wrong rpIdHash", func(authenticator *webauthntest.Authenticator) {
+			authenticator.RPID = "evil.example"
+		}, webauthn.ErrRPIDMismatch},
+		{"user not present", func(authenticator *webauthntest.Authenticator) {
+			authenticator.Flags = webauthn.FlagUserVerified
+		}, webauthn.ErrUserNotPresent},
+		{"user not verified", func(authenticator *webauthntest.Authenticator) {
+			authenticator.Flags = webauthn.FlagUserPresent
+		}, webauthn.ErrUserNotVerified},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			relyingParty := newRelyingParty()
+			authenticator := webauthntest.NewAuthenticator("example.com", "https://example.com")
+			test.modify(authenticator)
+
+			options := relyingParty.NewCreationOptions(testChallenge, webauthn.UserEntity{ID: "MQ", Name: "arthur"}, nil)
+			_, err := relyingParty.VerifyRegistration(testChallenge, authenticator.Register(options))
+			if !errors.Is(err, test.want) {
+				t.Errorf("VerifyRegistration error = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestVerifyRegistrationRejectsOtherChallenge(t *testing.T) {
+	relyingParty := newRelyingParty()
+	authenticator := webauthntest.NewAuthenticator("example.com", "https://example.com")
+
+	options := relyingParty.NewCreationOptions(testChallenge, webauthn.UserEntity{ID: "MQ", Name: "arthur"}, nil)
+	_, err := relyingParty.VerifyRegistration([]byte("another challenge"), authenticator.Register(options))
+	if !errors.Is(err, webauthn.ErrChallengeMismatch) {
+		t.Errorf("VerifyRegistration error = %v, want %v", err, webauthn.ErrChallengeMismatch)
+	}
+}
+
+func TestVerifyRegistrationRejectsMismatchedCredentialID(t *testing.T) {
+	relyingParty := newRelyingParty()
+	authenticator := webauthntest.NewAuthenticator("example.com", "https://example.com")
+
+	options := relyingParty.NewCreationOptions(testChallenge, webauthn.UserEntity{ID: "MQ", Name: "arthur"}, nil)
+	response := authenticator.Register(options)
+	response.RawID = webauthn.Encoding.EncodeToString([]byte("another credential"))
+
+	_, err := relyingParty.VerifyRegistration(testChallenge, response)
+	if !errors.Is(err, webauthn.ErrCredentialMismatch) {
+		t.Errorf("VerifyRegistration error = %v, want %v", err, webauthn.ErrCredentialMismatch)
+	}
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	relyingParty := newRelyingParty()
+	authenticator := webauthntest.NewAuthenticator("example.com", "https://example.com")
+	credential := register(t, relyingParty, authenticator)
+
+	options := relyingParty.NewRequestOptions(testChallenge, nil)
+	signCount, err := relyingParty.VerifyAssertion(testChallenge, credential.PublicKey, credential.SignCount, authenticator.Login(options))
+	if err != nil {
+		t.Fatalf("VerifyAssertion returned error: %v", err)
+	}
+	if signCount != 1 {
+		t.Errorf("sign count = %d, want 1", signCount)
+	}
+}
+
+func TestVerifyAssertionRejectsInvalidResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(authenticator *webauthntest.Authenticator)
+		want   error
+	}{
+		{"wrong origin", func(authenticator *webauthntest.Authenticator) {
+			authenticator.Origin = "https://evil.example"
+		}, webauthn.ErrOriginMismatch},
+		{"wrong rpIdHash", func(authenticator *webauthntest.Authenticator) {
+			authenticator.RPID = "evil.example"
+		}, webauthn.ErrRPIDMismatch},
+		{"user not verified", func(authenticator *webauthntest.Authenticator) {
+			authenticator.Flags = webauthn.FlagUserPresent
+		}, webauthn.ErrUserNotVerified},
+		{"sign count went backwards", func(authenticator *webauthntest.Authenticator) {
+			authenticator.SignCount = 3
+		}, webauthn.ErrSignCountRegression},
+		{"signed by another key", func(authenticator *webauthntest.Authenticator) {
+			authenticator.PrivateKey = webauthntest.NewAuthenticator("example.com", "https://example.com").PrivateKey
+		}, webauthn.ErrBadSignature},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			relyingParty := newRelyingParty()
+			authenticator := webauthntest.NewAuthenticator("example.com", "https://example.com")
+			credential := register(t, relyingParty, authenticator)
+			authenticator.SignCount = 10
+			test.modify(authenticator)
+
+			options := relyingParty.NewRequestOptions(testChallenge, nil)
+			_, err := relyingParty.VerifyAssertion(testChallenge, credential.PublicKey, 10, authenticator.Login(options))
+			if !errors.Is(err, test.want) {
+				t.Errorf("VerifyAssertion error = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestVerifyAssertionAllowsAuthenticatorsWithoutCounter(t *testing.T) {
+	relyingParty := newRelyingParty()
+	authenticator := webauthntest.NewAuthenticator("example.com", "https://example.com")
+	credential := register(t, relyingParty, authenticator)
+
+	// Authenticators that do not implement a counter always report zero.
+	authenticator.SignCount = ^uint32(0)
+	options := relyingParty.NewRequestOptions(testChallenge, nil)
+	signCount, err := relyingParty.VerifyAssertion(testChallenge, credential.PublicKey, 0, authenticator.Login(options))
+	if err != nil || signCount != 0 {
+		t.Errorf("VerifyAssertion = %d, %v; want 0, nil", signCount, err)
+	}
+}
+
+func TestVerifyAssertionAllowsMissingUserVerificationWhenNotRequired(t *testing.T) {
+	relyingParty := newRelyingParty()
+	relyingParty.RequireUserVerification = false
+	authenticator := webauthntest.NewAuthenticator("example.com", "https://example.com")
+	authenticator.Flags = webauthn.FlagUserPresent
+	credential := register(t, relyingParty, authenticator)
+
+	options := relyingParty.NewRequestOptions(testChallenge, nil)
+	_, err := relyingParty.VerifyAssertion(testChallenge, credential.PublicKey, credential.SignCount, authenticator.Login(options))
+	if err != nil {
+		t.Errorf("VerifyAssertion returned error: %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+// Package webauthntest provides a software authenticator for testing code
+// that verifies WebAuthn ceremonies.
+package webauthntest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"golang_jwt/webauthn"
+)
+
+// Authenticator is an ES256 platform authenticator holding one credential.
+// Its fields may be changed between ceremonies to produce invalid responses,
+// e.g. a foreign Origin or a SignCount that goes backwards.
+type Authenticator struct {
+	RPID         string
+	Origin       string
+	CredentialID []byte
+	PrivateKey   *ecdsa.PrivateKey
+	UserHandle   string
+	// Flags are set in the authenticator data; the attested credential flag
+	// is added on registration.
+	Flags byte
+	// SignCount is incremented before every assertion.
+	SignCount uint32
+}
+
+// NewAuthenticator returns an authenticator with a fresh key pair that
+// reports user presence and user verification.
+func NewAuthenticator(rpID string, origin string) *Authenticator {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	credentialID := make([]byte, 16)
+	_, err = rand.Read(credentialID)
+	if err != nil {
+		panic(err)
+	}
+
+	return &Authenticator{
+		RPID:         rpID,
+		Origin:       origin,
+		CredentialID: credentialID,
+		PrivateKey:   privateKey,
+		Flags:        webauthn.FlagUserPresent | webauthn.FlagUserVerified,
+	}
+}
+
+// Register answers creation options with a "none" attestation.
+func (authenticator *Authenticator) Register(options webauthn.CredentialCreationOptions) webauthn.RegistrationCredential {
+	clientDataJSON := authenticator.clientData(webauthn.CeremonyRegistration, options.Challenge)
+
+	attestedCredentialData := make([]byte, 18, 18+len(authenticator.CredentialID))
+	binary.BigEndian.PutUint16(attestedCredentialData[16:], uint16(len(authenticator.CredentialID)))
+	attestedCredentialData = append(attestedCredentialData, authenticator.CredentialID...)
+	attestedCredentialData = append(attestedCredentialData, authenticator.PublicKey()...)
+
+	authData := authenticator.authenticatorData(webauthn.FlagAttestedCredentialData)
+	authData = append(authData, attestedCredentialData...)
+
+	attestationObject := EncodeCBOR(map[interface{}]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[interface{}]interface{}{},
+		"authData": authData,
+	})
+
+	id := webauthn.Encoding.EncodeToString(authenticator.CredentialID)
+	return webauthn.RegistrationCredential{
+		ID:    id,
+		RawID: id,
+		Type:  webauthn.CredentialType,
+		Response: webauthn.AttestationResponse{
+			ClientDataJSON:    webauthn.Encoding.EncodeToString(clientDataJSON),
+			AttestationObject: webauthn.Encoding.EncodeToString(attestationObject),
+			Transports:        []string{"internal"},
+		},
+	}
+}
+
+// Login answers request options with a signed assertion.
+func (authenticator *Authenticator) Login(options webauthn.CredentialRequestOptions) webauthn.AssertionCredential {
+	authenticator.SignCount++
+	clientDataJSON := authenticator.clientData(webauthn.CeremonyAuthentication, options.Challenge)
+	authData := authenticator.authenticatorData(0)
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, authenticator.PrivateKey, digest[:])
+	if err != nil {
+		panic(err)
+	}
+
+	id := webauthn.Encoding.EncodeToString(authenticator.CredentialID)
+	return webauthn.AssertionCredential{
+		ID:    id,
+		RawID: id,
+		Type:  webauthn.CredentialType,
+		Response: webauthn.AssertionResponse{
+			ClientDataJSON:    webauthn.Encoding.EncodeToString(clientDataJSON),
+			AuthenticatorData: webauthn.Encoding.EncodeToString(authData),
+			Signature:         webauthn.Encoding.EncodeToString(signature),
+			UserHandle:        authenticator.UserHandle,
+		},
+	}
+}
+
+// PublicKey returns the credential public key as a COSE_Key.
+func (authenticator *Authenticator) PublicKey() []byte {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	authenticator.PrivateKey.X.FillBytes(x)
+	authenticator.PrivateKey.Y.FillBytes(y)
+
+	return EncodeCBOR(map[interface{}]interface{}{
+		int64(1):  int64(2),
+		int64(3):  webauthn.AlgES256,
+		int64(-1): int64(1),
+		int64(-2): x,
+		int64(-3): y,
+	})
+}
+
+func (authenticator *Authenticator) clientData(ceremony string, challenge string) []byte {
+	clientDataJSON, err := json.Marshal(webauthn.CollectedClientData{
+		Type:      ceremony,
+		Challenge: challenge,
+		Origin:    authenticator.Origin,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return clientDataJSON
+}
+
+func (authenticator *Authenticator) authenticatorData(flags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(authenticator.RPID))
+	authData := append([]byte{}, rpIDHash[:]...)
+	authData = append(authData, authenticator.Flags|flags)
+	return binary.BigEndian.AppendUint32(authData, authenticator.SignCount)
+}
@@ -0,0 +1,73 @@
+package webauthntest
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"slices"
+)
+
+// EncodeCBOR encodes the values an authenticator produces: int64, []byte,
+// string, bool, []interface{} and map[interface{}]interface{}. Map keys are
+// sorted as in CTAP2 canonical CBOR, so equal values encode identically.
+func EncodeCBOR(value interface{}) []byte {
+	return appendCBOR(nil, value)
+}
+
+func appendCBOR(data []byte, value interface{}) []byte {
+	switch value := value.(type) {
+	case int64:
+		if value < 0 {
+			return appendHead(data, 1, uint64(-1-value))
+		}
+		return appendHead(data, 0, uint64(value))
+	case []byte:
+		return append(appendHead(data, 2, uint64(len(value))), value...)
+	case string:
+		return append(appendHead(data, 3, uint64(len(value))), value...)
+	case bool:
+		if value {
+			return append(data, 0xf5)
+		}
+		return append(data, 0xf4)
+	case []interface{}:
+		data = appendHead(data, 4, uint64(len(value)))
+		for _, item := range value {
+			data = appendCBOR(data, item)
+		}
+		return data
+	case map[interface{}]interface{}:
+		keys := make([][]byte, 0, len(value))
+		items := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			encodedKey := appendCBOR(nil, key)
+			keys = append(keys, encodedKey)
+			items[string(encodedKey)] = item
+		}
+		slices.SortFunc(keys, func(a, b []byte) int {
+			return cmp.Or(cmp.Compare(len(a), len(b)), bytes.Compare(a, b))
+		})
+
+		data = appendHead(data, 5, uint64(len(value)))
+		for _, key := range keys {
+			data = appendCBOR(append(data, key...), items[string(key)])
+		}
+		return data
+	}
+	panic(fmt.Sprintf("webauthntest: cannot encode %T as CBOR", value))
+}
+
+func appendHead(data []byte, major byte, argument uint64) []byte {
+	switch {
+	case argument < 24:
+		return append(data, major<<5|byte(argument))
+	case argument <= 0xff:
+		return append(data, major<<5|24, byte(argument))
+	case argument <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(data, major<<5|25), uint16(argument))
+	case argument <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(data, major<<5|26), uint32(argument))
+	}
+	return binary.BigEndian.AppendUint64(append(data, major<<5|27), argument)
+}
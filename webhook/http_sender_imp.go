@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type HttpSenderImpl struct {
+	Client *http.Client
+}
+
+// NewHttpSender does not follow redirects, so a delivery only counts as
+// successful when the registered URL itself answered 2xx.
+func NewHttpSender(config Config) Sender {
+	return &HttpSenderImpl{
+		Client: &http.Client{
+			Timeout: config.Timeout,
+			CheckRedirect: func(request *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func (sender *HttpSenderImpl) Send(ctx context.Context, message Message) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, message.URL, bytes.NewReader(message.Body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(IdHeader, message.ID)
+	request.Header.Set(EventHeader, string(message.Event))
+	request.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(SignatureHeader, Sign(message.Secret, timestamp, message.Body))
+
+	response, err := sender.Client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	// Drain a little of the body so the connection can be reused.
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+	return response.StatusCode, nil
+}
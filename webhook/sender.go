@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventUserRegistered  EventType = "user.registered"
+	EventLoginFailed     EventType = "login.failed"
+	EventSessionRevoked  EventType = "session.revoked"
+	EventPasswordChanged EventType = "password.changed"
+)
+
+// EventTypes lists the events an endpoint can subscribe to.
+var EventTypes = []EventType{EventUserRegistered, EventLoginFailed, EventSessionRevoked, EventPasswordChanged}
+
+const (
+	IdHeader        = "Webhook-Id"
+	EventHeader     = "Webhook-Event"
+	TimestampHeader = "Webhook-Timestamp"
+	SignatureHeader = "Webhook-Signature"
+)
+
+// Payload is the JSON body of every webhook. Data holds the event specific
+// fields; the client details are those of the request that caused the event.
+type Payload struct {
+	ID         string                 `json:"id"`
+	Type       EventType              `json:"type"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	IPAddress  string                 `json:"ip_address,omitempty"`
+	UserAgent  string                 `json:"user_agent,omitempty"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+// Message is one delivery attempt. ID stays the same across retries, so
+// receivers can drop duplicates.
+type Message struct {
+	ID     string
+	Event  EventType
+	URL    string
+	Secret string
+	Body   []byte
+}
+
+// Sender posts a message and returns the response status. An error means
+// no response was received.
+type Sender interface {
+	Send(ctx context.Context, message Message) (int, error)
+}
+
+type Config struct {
+	Timeout time.Duration
+}
+
+// Sign returns the signature header value for a body sent at timestamp:
+// the hex HMAC-SHA256 of "timestamp.body" under the endpoint's secret. The
+// timestamp is signed so receivers can reject replayed requests.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}